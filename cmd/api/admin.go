@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"apiapp/internal/jobs"
+	"apiapp/internal/request"
+	"apiapp/internal/response"
+	"apiapp/internal/settings"
+	"apiapp/internal/validator"
+
+	"github.com/gorilla/mux"
+)
+
+// adminRoutes возвращает HTTP-обработчик служебного сервера: профилирование pprof, метрики expvar,
+// статистику среды выполнения, подробное состояние проверок, управление уровнем логирования, конфигурацию
+// и неудавшиеся фоновые задачи.
+// Все маршруты защищены отдельными учетными данными администратора.
+func (app *application) adminRoutes() http.Handler {
+	mux := mux.NewRouter()
+
+	mux.NotFoundHandler = http.HandlerFunc(app.notFound)
+	mux.MethodNotAllowedHandler = http.HandlerFunc(app.methodNotAllowed)
+
+	app.use(mux, app.recoverPanic, app.requireAdminAuthentication)
+
+	// Эндпоинты профилирования из пакета net/http/pprof.
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+
+	// Метрики, опубликованные через пакет expvar.
+	mux.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+
+	mux.HandleFunc("/runtime", app.adminRuntime).Methods("GET")
+	mux.HandleFunc("/health", app.adminHealth).Methods("GET")
+	mux.HandleFunc("/log-level", app.adminLogLevel).Methods("GET")
+	mux.HandleFunc("/log-level", app.adminSetLogLevel).Methods("PUT")
+	mux.HandleFunc("/config", app.adminConfig).Methods("GET")
+	mux.HandleFunc("/maintenance", app.adminMaintenance).Methods("GET")
+	mux.HandleFunc("/maintenance", app.adminSetMaintenance).Methods("PUT")
+	mux.HandleFunc("/drain", app.adminDrain).Methods("POST")
+	mux.HandleFunc("/jobs", app.adminJobs).Methods("GET")
+	mux.HandleFunc("/jobs/dead-letters", app.adminDeadLetters).Methods("GET")
+	mux.HandleFunc("/jobs/dead-letters/{id}", app.adminDeadLetter).Methods("GET")
+	mux.HandleFunc("/jobs/dead-letters/{id}/retry", app.adminRetryDeadLetter).Methods("POST")
+
+	return mux
+}
+
+// adminRuntime возвращает статистику среды выполнения Go: горутины, память и сборку мусора.
+func (app *application) adminRuntime(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	data := map[string]interface{}{
+		"uptime":         time.Since(app.startedAt).Round(time.Second).String(),
+		"go_version":     runtime.Version(),
+		"goroutines":     runtime.NumGoroutine(),
+		"cpus":           runtime.NumCPU(),
+		"gomaxprocs":     runtime.GOMAXPROCS(0),
+		"heap_alloc":     mem.HeapAlloc,
+		"heap_inuse":     mem.HeapInuse,
+		"heap_objects":   mem.HeapObjects,
+		"sys":            mem.Sys,
+		"total_alloc":    mem.TotalAlloc,
+		"num_gc":         mem.NumGC,
+		"gc_pause_total": time.Duration(mem.PauseTotalNs).String(),
+	}
+
+	err := response.JSON(w, http.StatusOK, data)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminHealth возвращает результаты liveness- и readiness-проверок со всеми подробностями.
+func (app *application) adminHealth(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{
+		"liveness":  app.health.Liveness(r.Context()),
+		"readiness": app.health.Readiness(r.Context()),
+	}
+
+	err := response.JSON(w, http.StatusOK, data)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminLogLevel возвращает текущий уровень логирования.
+func (app *application) adminLogLevel(w http.ResponseWriter, r *http.Request) {
+	data := map[string]string{
+		"level": app.logLevel.Level().String(),
+	}
+
+	err := response.JSON(w, http.StatusOK, data)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminSetLogLevel изменяет уровень логирования без перезапуска приложения.
+// Принимает JSON вида {"level": "debug"}; допустимы уровни debug, info, warn и error.
+func (app *application) adminSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Level string `json:"level"`
+	}
+
+	err := request.DecodeJSONStrict(w, r, &input)
+	if err != nil {
+		app.decodeError(w, r, err)
+		return
+	}
+
+	// Разбор уровня в формате slog, например "warn" или "info+2".
+	var level slog.Level
+	var v validator.Validator
+	v.CheckField(level.UnmarshalText([]byte(strings.TrimSpace(input.Level))) == nil, "level", "must be one of: debug, info, warn, error")
+	if v.HasErrors() {
+		app.failedValidation(w, r, v)
+		return
+	}
+
+	app.logLevel.Set(level)
+	app.logger.Info("log level changed", "level", level.String())
+
+	app.adminLogLevel(w, r)
+}
+
+// adminConfig возвращает действующие значения параметров конфигурации с источником каждого значения.
+// Значения секретных параметров, например хешей паролей, не раскрываются.
+func (app *application) adminConfig(w http.ResponseWriter, r *http.Request) {
+	cfg := app.config()
+
+	var data []map[string]string
+	for _, value := range settings.Explain(cfg, cfg.sources...) {
+		data = append(data, map[string]string{
+			"key":    value.Key,
+			"value":  value.Value,
+			"source": value.Source,
+		})
+	}
+
+	err := response.JSON(w, http.StatusOK, data)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminMaintenance возвращает состояние режима обслуживания и число выполняющихся запросов.
+func (app *application) adminMaintenance(w http.ResponseWriter, r *http.Request) {
+	mode := app.currentMaintenance()
+
+	data := map[string]interface{}{
+		"enabled":     mode.Enabled,
+		"message":     mode.Message,
+		"retry_after": mode.RetryAfter.String(),
+		"in_flight":   app.inFlight.Load(),
+	}
+	if mode.Enabled {
+		data["since"] = mode.Since
+	}
+
+	err := response.JSON(w, http.StatusOK, data)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminSetMaintenance включает или выключает режим обслуживания.
+// Принимает JSON вида {"enabled": true, "message": "...", "retry_after": "5m"};
+// незаданные сообщение и время повторной попытки берутся из конфигурации.
+func (app *application) adminSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Enabled    bool   `json:"enabled"`
+		Message    string `json:"message"`
+		RetryAfter string `json:"retry_after"`
+	}
+
+	err := request.DecodeJSONStrict(w, r, &input)
+	if err != nil {
+		app.decodeError(w, r, err)
+		return
+	}
+
+	mode := maintenanceMode{
+		Enabled:    input.Enabled,
+		Message:    app.config().Maintenance.Message,
+		RetryAfter: app.config().Maintenance.RetryAfter,
+	}
+	if input.Message != "" {
+		mode.Message = input.Message
+	}
+
+	// Разбор времени повторной попытки в формате time.ParseDuration.
+	var v validator.Validator
+	if input.RetryAfter != "" {
+		retryAfter, err := time.ParseDuration(input.RetryAfter)
+		v.CheckField(err == nil && retryAfter >= 0, "retry_after", "must be a non-negative duration, like 30s or 5m")
+		mode.RetryAfter = retryAfter
+	}
+	if v.HasErrors() {
+		app.failedValidation(w, r, v)
+		return
+	}
+
+	// Сохранение времени включения, если режим уже был включен.
+	if current := app.currentMaintenance(); current.Enabled && mode.Enabled {
+		mode.Since = current.Since
+	}
+
+	app.setMaintenance(mode)
+	app.adminMaintenance(w, r)
+}
+
+// adminDrain выводит экземпляр из ротации: включает режим обслуживания и ожидает завершения выполняющихся
+// запросов, но не дольше тайм-аута из параметра timeout (по умолчанию - период завершения сервера).
+// Возвращает 200 (OK), если все запросы завершены, и 504 (Gateway Timeout), если тайм-аут истек.
+func (app *application) adminDrain(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Timeout time.Duration `query:"timeout"`
+	}
+	input.Timeout = app.config().Server.ShutdownPeriod
+
+	err := request.DecodeQuery(r, &input)
+	if err != nil {
+		var fieldErrors request.FieldErrors
+		if !errors.As(err, &fieldErrors) {
+			app.serverError(w, r, err)
+			return
+		}
+
+		var v validator.Validator
+		for key, message := range fieldErrors {
+			v.AddFieldError(key, message)
+		}
+		app.failedValidation(w, r, v)
+		return
+	}
+
+	// Включение режима обслуживания, если он еще не включен.
+	if !app.currentMaintenance().Enabled {
+		app.setMaintenance(maintenanceMode{
+			Enabled:    true,
+			Message:    app.config().Maintenance.Message,
+			RetryAfter: app.config().Maintenance.RetryAfter,
+		})
+	}
+
+	// Ожидание завершения выполняющихся запросов.
+	ctx, cancel := context.WithTimeout(r.Context(), input.Timeout)
+	defer cancel()
+
+	drained := app.waitInFlight(ctx)
+
+	status := http.StatusOK
+	if !drained {
+		status = http.StatusGatewayTimeout
+	}
+
+	data := map[string]interface{}{
+		"drained":   drained,
+		"in_flight": app.inFlight.Load(),
+	}
+
+	err = response.JSON(w, status, data)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminJobs возвращает метрики пула фоновых задач: размер очереди, число выполняющихся задач и счетчики результатов.
+func (app *application) adminJobs(w http.ResponseWriter, r *http.Request) {
+	err := response.JSON(w, http.StatusOK, app.jobs.Stats())
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminDeadLetters возвращает список фоновых задач, завершившихся ошибкой после всех попыток, начиная с последней.
+// Для каждой задачи возвращаются число попыток и ошибка последней попытки; подробности - в adminDeadLetter.
+func (app *application) adminDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deadLetters := app.jobs.DeadLetters()
+
+	items := []map[string]interface{}{}
+	for _, dl := range deadLetters.List() {
+		items = append(items, map[string]interface{}{
+			"id":        dl.ID,
+			"name":      dl.Name,
+			"attempts":  len(dl.Attempts),
+			"error":     dl.LastError(),
+			"failed_at": dl.FailedAt,
+		})
+	}
+
+	data := map[string]interface{}{
+		"dead_letters": items,
+		"dropped":      deadLetters.Dropped(),
+	}
+
+	err := response.JSON(w, http.StatusOK, data)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminDeadLetter возвращает неудавшуюся фоновую задачу со всеми попытками и их ошибками.
+func (app *application) adminDeadLetter(w http.ResponseWriter, r *http.Request) {
+	dl, ok := app.jobs.DeadLetters().Get(mux.Vars(r)["id"])
+	if !ok {
+		app.notFound(w, r)
+		return
+	}
+
+	err := response.JSON(w, http.StatusOK, dl)
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// adminRetryDeadLetter удаляет неудавшуюся фоновую задачу из хранилища и снова ставит ее в очередь
+// с новым счетчиком попыток. Возвращает 202 (Accepted), если задача поставлена в очередь, и 503
+// (Service Unavailable), если очередь заполнена или приложение завершает работу.
+func (app *application) adminRetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	err := app.jobs.Requeue(r.Context(), id)
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		app.notFound(w, r)
+		return
+	case errors.Is(err, jobs.ErrQueueFull), errors.Is(err, jobs.ErrClosed):
+		app.serviceUnavailable(w, r, "Очередь фоновых задач недоступна, повторите попытку позже", 0)
+		return
+	case err != nil:
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.logger.Info("dead letter requeued", "id", id)
+
+	err = response.JSON(w, http.StatusAccepted, map[string]string{"id": id, "status": "queued"})
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// waitInFlight ожидает, пока счетчик выполняющихся запросов не станет равным нулю.
+// Возвращает false, если контекст был отменен раньше.
+func (app *application) waitInFlight(ctx context.Context) bool {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for app.inFlight.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,679 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"apiapp/internal/env"
+	"apiapp/internal/health"
+	"apiapp/internal/secrets"
+	"apiapp/internal/settings"
+	"apiapp/internal/version"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Структура cli содержит ввод-вывод и общие параметры команд приложения.
+type cli struct {
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	logger   *slog.Logger
+	logLevel *slog.LevelVar
+
+	// Общие флаги командной строки, путь к файлу конфигурации и флаги параметров конфигурации.
+	global     *flag.FlagSet
+	configFile string
+	flags      *settings.Flags
+}
+
+// Структура command описывает команду приложения.
+type command struct {
+	name    string // Имя команды, например "config check".
+	args    string // Аргументы команды для справки.
+	summary string // Краткое описание для списка команд.
+	help    string // Подробное описание для справки по команде.
+	run     func(args []string) error
+}
+
+// exitError - ошибка команды с кодом завершения. Сообщение выводится в stderr без трассировки стека.
+// Если err равно nil, сообщение об ошибке уже выведено.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// commands возвращает список команд приложения.
+func (c *cli) commands() []command {
+	return []command{
+		{
+			name:    "serve",
+			summary: "запустить HTTP-сервер (команда по умолчанию)",
+			help:    "Загружает конфигурацию и запускает HTTP-сервер. Выполняется, если команда не указана.",
+			run:     c.serve,
+		},
+		{
+			name:    "version",
+			args:    "[-json]",
+			summary: "вывести версию и сведения о сборке",
+			help:    "Выводит версию приложения, ревизию VCS, время коммита и версию Go.",
+			run:     c.version,
+		},
+		{
+			name:    "hash-password",
+			args:    "[-cost N] < пароль",
+			summary: "вычислить bcrypt-хеш пароля",
+			help: "Читает пароль из первой строки стандартного ввода и выводит его bcrypt-хеш для параметров\n" +
+				"BASIC_AUTH_HASHED_PASSWORD и ADMIN_HASHED_PASSWORD.",
+			run: c.hashPassword,
+		},
+		{
+			name:    "config check",
+			summary: "проверить конфигурацию",
+			help:    "Загружает конфигурацию из всех источников и проверяет ее. При ошибках выводит отчет и завершается с кодом 2.",
+			run:     c.configCheck,
+		},
+		{
+			name:    "config print",
+			summary: "вывести действующую конфигурацию с источниками значений",
+			help:    "Выводит значения всех параметров после объединения источников и источник каждого значения.\nСекретные значения скрываются.",
+			run:     c.configPrint,
+		},
+		{
+			name:    "secrets encrypt",
+			args:    "< secrets.json > secrets.enc",
+			summary: "зашифровать файл секретов",
+			help:    "Шифрует JSON-объект со значениями секретов из стандартного ввода мастер-ключом из SECRETS_MASTER_KEY\nили SECRETS_MASTER_KEY_FILE.",
+			run:     c.secretsEncrypt,
+		},
+		{
+			name:    "secrets decrypt",
+			args:    "< secrets.enc > secrets.json",
+			summary: "расшифровать файл секретов",
+			help:    "Расшифровывает файл секретов из стандартного ввода мастер-ключом из SECRETS_MASTER_KEY\nили SECRETS_MASTER_KEY_FILE и выводит JSON-объект со значениями.",
+			run:     c.secretsDecrypt,
+		},
+		{
+			name:    "routes",
+			args:    "[-admin]",
+			summary: "вывести список маршрутов",
+			help:    "Выводит все маршруты с HTTP-методами, обработчиками и middleware в порядке их вызова.",
+			run:     c.routes,
+		},
+		{
+			name:    "healthcheck",
+			args:    "[-url URL] [-timeout D] [-live]",
+			summary: "проверить состояние запущенного экземпляра",
+			help: "Отправляет запрос к /readyz (или /livez с флагом -live) запущенного экземпляра и завершается\n" +
+				"с кодом 0, если он готов, или с кодом 1 в противном случае. Подходит для HEALTHCHECK в Docker.\n" +
+				"По умолчанию адрес берется из первого значения HTTP_LISTEN.",
+			run: c.healthcheck,
+		},
+		{
+			name:    "help",
+			args:    "[команда]",
+			summary: "вывести справку по команде",
+			help:    "Выводит список команд или справку по указанной команде.",
+			run:     c.help,
+		},
+	}
+}
+
+// run разбирает общие флаги командной строки и выполняет команду из аргументов args.
+func (c *cli) run(args []string) error {
+	// Загрузка переменных окружения из dotenv-файлов для локальной разработки. Файлы выбираются по значению
+	// APP_ENV, переменные, уже заданные в окружении, не переопределяются.
+	loaded, err := env.Load(env.Files(os.Getenv("APP_ENV"))...)
+	if err != nil {
+		return err
+	}
+	for _, v := range loaded {
+		c.logger.Debug("environment variable loaded from file", "key", v.Key, "file", v.File, "line", v.Line)
+	}
+
+	// Общие флаги: флаги версии и файла конфигурации, а также флаги, созданные для каждого параметра конфигурации.
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = c.usage
+	c.global = fs
+
+	var showVersion versionFlag
+	fs.Var(&showVersion, "version", "отобразить версию и завершить программу (-version=json для вывода в формате JSON)")
+	fs.StringVar(&c.configFile, "config", "", "путь к файлу конфигурации в формате YAML, TOML или JSON (или переменная CONFIG_FILE)")
+	c.flags = settings.NewFlags(fs, &config{})
+
+	err = fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return &exitError{code: 2}
+	}
+
+	// Флаг -version равнозначен команде version.
+	if showVersion != "" {
+		return printVersion(c.stdout, string(showVersion))
+	}
+
+	cmd, cmdArgs, ok := c.lookup(fs.Args())
+	if !ok {
+		fmt.Fprintf(c.stderr, "неизвестная команда %q\n", strings.Join(fs.Args(), " "))
+		fmt.Fprintln(c.stderr, "Список команд: api help")
+		return &exitError{code: 2}
+	}
+
+	err = cmd.run(cmdArgs)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return nil
+	case cmd.name == "serve":
+		// Ошибки сервера логгируются вместе с трассировкой стека.
+		return err
+	}
+
+	var e *exitError
+	if errors.As(err, &e) {
+		return err
+	}
+	return &exitError{code: 1, err: fmt.Errorf("%s: %w", cmd.name, err)}
+}
+
+// lookup находит команду по первым одному или двум аргументам. Без аргументов выбирается команда serve.
+func (c *cli) lookup(args []string) (command, []string, bool) {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	for _, cmd := range c.commands() {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
+		}
+	}
+
+	return command{}, nil, false
+}
+
+// flagSet создает набор флагов команды name, который при ошибке или флаге -h выводит справку по команде.
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() { c.commandUsage(name, fs) }
+	return fs
+}
+
+// parseFlags разбирает флаги команды. Лишние позиционные аргументы считаются ошибкой.
+func (c *cli) parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	if err == nil && fs.NArg() > 0 {
+		fmt.Fprintf(c.stderr, "лишние аргументы: %s\n", strings.Join(fs.Args(), " "))
+		fs.Usage()
+		err = errors.New("unexpected arguments")
+	}
+	if err != nil {
+		return &exitError{code: 2}
+	}
+	return nil
+}
+
+// usage выводит общую справку: список команд и общие флаги.
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "Использование: api [общие флаги] [команда] [аргументы]")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Команды:")
+
+	tw := tabwriter.NewWriter(c.stderr, 0, 0, 2, ' ', 0)
+	for _, cmd := range c.commands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Справка по команде: api help <команда> или api <команда> -h")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Общие флаги:")
+	c.global.PrintDefaults()
+}
+
+// commandUsage выводит справку по команде name и ее флагам.
+func (c *cli) commandUsage(name string, fs *flag.FlagSet) {
+	for _, cmd := range c.commands() {
+		if cmd.name != name {
+			continue
+		}
+
+		fmt.Fprintf(c.stderr, "Использование: api [общие флаги] %s %s\n\n%s\n", cmd.name, cmd.args, cmd.help)
+
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(c.stderr)
+			fmt.Fprintln(c.stderr, "Флаги:")
+			fs.PrintDefaults()
+		}
+	}
+}
+
+// help выводит список команд или справку по команде.
+func (c *cli) help(args []string) error {
+	if len(args) == 0 {
+		c.usage()
+		return nil
+	}
+
+	cmd, rest, ok := c.lookup(args)
+	if !ok || len(rest) > 0 {
+		fmt.Fprintf(c.stderr, "неизвестная команда %q\n", strings.Join(args, " "))
+		return &exitError{code: 2}
+	}
+
+	// Справка по команде выводится ее собственным набором флагов.
+	return cmd.run([]string{"-h"})
+}
+
+// version выводит сведения о версии и сборке приложения.
+func (c *cli) version(args []string) error {
+	fs := c.flagSet("version")
+	asJSON := fs.Bool("json", false, "вывести сведения в формате JSON")
+	err := c.parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	format := "text"
+	if *asJSON {
+		format = "json"
+	}
+	return printVersion(c.stdout, format)
+}
+
+// hashPassword читает пароль из первой строки стандартного ввода и выводит его bcrypt-хеш.
+func (c *cli) hashPassword(args []string) error {
+	fs := c.flagSet("hash-password")
+	cost := fs.Int("cost", bcrypt.DefaultCost, fmt.Sprintf("сложность хеширования (от %d до %d)", bcrypt.MinCost, bcrypt.MaxCost))
+	err := c.parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *cost < bcrypt.MinCost || *cost > bcrypt.MaxCost {
+		return fmt.Errorf("cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	password, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return errors.New("password must be provided on stdin")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), *cost)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.stdout, string(hash))
+	return err
+}
+
+// configCheck загружает и проверяет конфигурацию.
+func (c *cli) configCheck(args []string) error {
+	err := c.parseFlags(c.flagSet("config check"), args)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.stdout, "конфигурация корректна")
+	return err
+}
+
+// configPrint выводит действующую конфигурацию с источником каждого значения. Ошибки конфигурации
+// выводятся после таблицы.
+func (c *cli) configPrint(args []string) error {
+	err := c.parseFlags(c.flagSet("config print"), args)
+	if err != nil {
+		return err
+	}
+
+	sources, _, err := configSources(c.configFile, c.flags)
+	if err != nil {
+		return err
+	}
+
+	var cfg config
+	printConfig(c.stdout, settings.Explain(&cfg, sources...))
+	return settings.Load(&cfg, sources...)
+}
+
+// secretsEncrypt шифрует файл секретов.
+func (c *cli) secretsEncrypt(args []string) error {
+	err := c.parseFlags(c.flagSet("secrets encrypt"), args)
+	if err != nil {
+		return err
+	}
+	return encryptSecrets(c.stdin, c.stdout)
+}
+
+// secretsDecrypt расшифровывает файл секретов.
+func (c *cli) secretsDecrypt(args []string) error {
+	err := c.parseFlags(c.flagSet("secrets decrypt"), args)
+	if err != nil {
+		return err
+	}
+	return decryptSecrets(c.stdin, c.stdout)
+}
+
+// routes выводит таблицу маршрутов основного или служебного сервера.
+func (c *cli) routes(args []string) error {
+	fs := c.flagSet("routes")
+	admin := fs.Bool("admin", false, "вывести маршруты служебного сервера")
+	err := c.parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	// Для построения маршрутов достаточно приложения без конфигурации.
+	app := &application{logger: c.logger, health: health.New(defaultHealthCacheTTL)}
+
+	handler := app.routes()
+	if *admin {
+		handler = app.adminRoutes()
+	}
+
+	router, ok := handler.(*mux.Router)
+	if !ok {
+		return errors.New("routes are not served by a gorilla/mux router")
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHODS\tPATH\tHANDLER\tMIDDLEWARE")
+
+	err = router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		// Маршруты без обработчика - это подмаршруты, их маршруты обходятся отдельно.
+		if route.GetHandler() == nil {
+			return nil
+		}
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"*"}
+		}
+
+		middleware := strings.Join(app.middlewareChain(router), ", ")
+		if middleware == "" {
+			middleware = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", strings.Join(methods, ","), path, handlerName(route.GetHandler()), middleware)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Flush()
+}
+
+// healthcheck проверяет готовность запущенного экземпляра приложения.
+func (c *cli) healthcheck(args []string) error {
+	fs := c.flagSet("healthcheck")
+	target := fs.String("url", "", "адрес проверки, например http://localhost:4444/readyz (по умолчанию из HTTP_LISTEN)")
+	timeout := fs.Duration("timeout", 3*time.Second, "тайм-аут запроса")
+	live := fs.Bool("live", false, "проверять /livez вместо /readyz")
+	err := c.parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: *timeout}
+
+	// Адрес проверки по умолчанию определяется по первому адресу из конфигурации.
+	if *target == "" {
+		cfg, _, err := c.loadConfig()
+		if err != nil {
+			return err
+		}
+
+		path := "/readyz"
+		if *live {
+			path = "/livez"
+		}
+
+		*target, client.Transport = healthcheckTarget(cfg.HTTPListen[0], path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, *target, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", *target, res.Status)
+	}
+
+	_, err = fmt.Fprintf(c.stdout, "%s: %s\n", *target, res.Status)
+	return err
+}
+
+// healthcheckTarget возвращает адрес проверки для адреса прослушивания listen и транспорт для сокетов Unix.
+// Для адресов без хоста или с адресом всех интерфейсов запрос отправляется на localhost.
+func healthcheckTarget(listen, path string) (string, http.RoundTripper) {
+	network, addr := parseListenAddress(listen)
+	if network == "unix" {
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", addr)
+			},
+		}
+		return "http://localhost" + path, transport
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err == nil {
+		if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+			host = "localhost"
+		}
+		addr = net.JoinHostPort(host, port)
+	}
+
+	return "http://" + addr + path, nil
+}
+
+// loadConfig загружает и проверяет конфигурацию из всех источников с учетом общих флагов.
+// Также возвращает пути к файлам, изменения которых отслеживаются для перезагрузки конфигурации.
+func (c *cli) loadConfig() (*config, []string, error) {
+	sources, watchPaths, err := configSources(c.configFile, c.flags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cfg config
+	err = loadConfig(&cfg, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &cfg, watchPaths, nil
+}
+
+var (
+	// closureSuffix соответствует суффиксам имен анонимных функций и методов-значений, например ".func1" или "-fm".
+	closureSuffix = regexp.MustCompile(`(\.func\d+)+$|-fm$`)
+
+	// applicationPrefix соответствует префиксу имен методов application: "main.(*application)." в приложении
+	// или "apiapp/cmd/api.(*application)." в тестах.
+	applicationPrefix = regexp.MustCompile(`^.*\.\(\*application\)\.`)
+)
+
+// handlerName возвращает читаемое имя обработчика, например "app.livez" для метода application.
+func handlerName(h http.Handler) string {
+	if fn, ok := h.(http.HandlerFunc); ok {
+		return funcName(fn)
+	}
+	return fmt.Sprintf("%T", h)
+}
+
+// funcName возвращает читаемое имя функции. Методы application записываются как "app.<метод>",
+// а для замыканий возвращается имя функции, которая их создала.
+func funcName(fn interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = closureSuffix.ReplaceAllString(name, "")
+	return applicationPrefix.ReplaceAllString(name, "app.")
+}
+
+// encryptSecrets читает JSON-объект со значениями секретов из r и записывает в w зашифрованный файл секретов.
+func encryptSecrets(r io.Reader, w io.Writer) error {
+	masterKey, err := secretsMasterKey()
+	if err != nil {
+		return err
+	}
+
+	var values map[string]string
+	err = json.NewDecoder(r).Decode(&values)
+	if err != nil {
+		return fmt.Errorf("secrets must be a JSON object with string values: %w", err)
+	}
+
+	data, err := secrets.Encrypt(values, masterKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// decryptSecrets читает зашифрованный файл секретов из r и записывает в w JSON-объект со значениями.
+func decryptSecrets(r io.Reader, w io.Writer) error {
+	masterKey, err := secretsMasterKey()
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	values, err := secrets.Decrypt(data, masterKey)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(values)
+}
+
+// printConfig выводит таблицу действующих значений конфигурации с источником каждого значения.
+func printConfig(w io.Writer, values []settings.Value) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+
+	for _, value := range values {
+		source := value.Source
+		if source == "" {
+			source = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", value.Key, value.Value, source)
+	}
+
+	tw.Flush()
+}
+
+// versionFlag - значение флага -version: "text" при указании флага без значения или "json".
+type versionFlag string
+
+func (f *versionFlag) String() string { return string(*f) }
+
+func (f *versionFlag) Set(value string) error {
+	switch value {
+	case "true", "text":
+		*f = "text"
+	case "json":
+		*f = "json"
+	case "false":
+		*f = ""
+	default:
+		return errors.New("must be text or json")
+	}
+	return nil
+}
+
+// IsBoolFlag позволяет указывать флаг -version без значения.
+func (f *versionFlag) IsBoolFlag() bool { return true }
+
+// printVersion выводит сведения о версии и сборке приложения в текстовом виде или в формате JSON.
+func printVersion(w io.Writer, format string) error {
+	info := version.Read()
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(info)
+	}
+
+	fmt.Fprintf(w, "версия: %s\n", info.Version)
+	if info.Revision != "" {
+		dirty := ""
+		if info.Dirty {
+			dirty = " (есть незафиксированные изменения)"
+		}
+		fmt.Fprintf(w, "ревизия: %s%s\n", info.Revision, dirty)
+	}
+	if info.CommitTime != "" {
+		fmt.Fprintf(w, "время коммита: %s\n", info.CommitTime)
+	}
+	_, err := fmt.Fprintf(w, "версия Go: %s\n", info.GoVersion)
+	return err
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"apiapp/internal/settings"
+	"apiapp/internal/version"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestCLI создает cli для тестов с заданным стандартным вводом и буферами вывода.
+func newTestCLI(stdin string) (*cli, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	c := &cli{
+		stdin:    strings.NewReader(stdin),
+		stdout:   &stdout,
+		stderr:   &stderr,
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		logLevel: new(slog.LevelVar),
+	}
+	return c, &stdout, &stderr
+}
+
+// exitCode возвращает код завершения для ошибки команды.
+func exitCode(err error) int {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// Тестирование справки и неизвестных команд.
+func TestCLIHelp(t *testing.T) {
+	c, _, stderr := newTestCLI("")
+	err := c.run([]string{"help"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cmd := range c.commands() {
+		if !strings.Contains(stderr.String(), cmd.name) {
+			t.Errorf("expected help to list %q", cmd.name)
+		}
+	}
+
+	c, _, stderr = newTestCLI("")
+	err = c.run([]string{"help", "config", "check"})
+	if err != nil || !strings.Contains(stderr.String(), "Использование: api [общие флаги] config check") {
+		t.Errorf("unexpected command help: %v, %q", err, stderr.String())
+	}
+
+	for _, args := range [][]string{{"bogus"}, {"config"}, {"version", "extra"}, {"routes", "-bogus"}} {
+		c, _, _ = newTestCLI("")
+		if code := exitCode(c.run(args)); code != 2 {
+			t.Errorf("%v: expected exit code 2, got %d", args, code)
+		}
+	}
+}
+
+// Тестирование команды version и флага -version.
+func TestCLIVersion(t *testing.T) {
+	c, stdout, _ := newTestCLI("")
+	err := c.run([]string{"version", "-json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var info version.Info
+	err = json.Unmarshal(stdout.Bytes(), &info)
+	if err != nil || info != version.Read() {
+		t.Errorf("unexpected version info: %v, %s", err, stdout)
+	}
+
+	c, stdout, _ = newTestCLI("")
+	err = c.run([]string{"-version"})
+	if err != nil || !strings.HasPrefix(stdout.String(), "версия: "+version.Get()) {
+		t.Errorf("unexpected version output: %v, %q", err, stdout)
+	}
+}
+
+// Тестирование команды hash-password.
+func TestCLIHashPassword(t *testing.T) {
+	c, stdout, _ := newTestCLI("s3cr3t\n")
+	err := c.run([]string{"hash-password", "-cost", "4"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hash := strings.TrimSpace(stdout.String())
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte("s3cr3t")) != nil {
+		t.Errorf("hash %q doesn't match the password", hash)
+	}
+
+	// Пустой пароль и недопустимая сложность.
+	for _, args := range [][]string{{"hash-password"}, {"hash-password", "-cost", "100"}} {
+		c, _, _ = newTestCLI("")
+		if code := exitCode(c.run(args)); code != 1 {
+			t.Errorf("%v: expected exit code 1, got %d", args, code)
+		}
+	}
+}
+
+// Тестирование команды config check.
+func TestCLIConfigCheck(t *testing.T) {
+	c, stdout, _ := newTestCLI("")
+	err := c.run([]string{"config", "check"})
+	if err != nil || !strings.Contains(stdout.String(), "конфигурация корректна") {
+		t.Errorf("unexpected result: %v, %q", err, stdout)
+	}
+
+	// Ошибки конфигурации возвращаются в виде отчета.
+	c, _, _ = newTestCLI("")
+	err = c.run([]string{"-http-port=0", "config", "check"})
+	var configError *settings.Error
+	if !errors.As(err, &configError) || configError.Problems[0].Key != "HTTP_PORT" {
+		t.Errorf("expected configuration error for HTTP_PORT, got %v", err)
+	}
+}
+
+// Тестирование команды routes.
+func TestCLIRoutes(t *testing.T) {
+	c, stdout, _ := newTestCLI("")
+	err := c.run([]string{"routes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "GET      /basic-auth-protected  app.protected    app.recoverPanic, app.checkMaintenance, app.limitRequestBody, app.requireBasicAuthentication"
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("expected routes to contain %q, got:\n%s", want, stdout)
+	}
+
+	c, stdout, _ = newTestCLI("")
+	err = c.run([]string{"routes", "-admin"})
+	if err != nil || !strings.Contains(stdout.String(), "/maintenance") {
+		t.Errorf("expected admin routes, got %v:\n%s", err, stdout)
+	}
+}
+
+// Тестирование команды healthcheck.
+func TestCLIHealthcheck(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	c, _, _ := newTestCLI("")
+	err := c.run([]string{"healthcheck", "-url", srv.URL + "/readyz"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	c, _, _ = newTestCLI("")
+	if code := exitCode(c.run([]string{"healthcheck", "-url", srv.URL + "/readyz"})); code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+}
+
+// Тестирование выбора адреса проверки по адресу прослушивания.
+func TestHealthcheckTarget(t *testing.T) {
+	tests := map[string]string{
+		":4444":          "http://localhost:4444/readyz",
+		"0.0.0.0:4444":   "http://localhost:4444/readyz",
+		"[::]:4444":      "http://localhost:4444/readyz",
+		"127.0.0.1:9000": "http://127.0.0.1:9000/readyz",
+		"unix:/api.sock": "http://localhost/readyz",
+	}
+
+	for listen, want := range tests {
+		target, transport := healthcheckTarget(listen, "/readyz")
+		if target != want {
+			t.Errorf("%s: expected %q, got %q", listen, want, target)
+		}
+		if (transport != nil) != strings.HasPrefix(listen, "unix:") {
+			t.Errorf("%s: unexpected transport %v", listen, transport)
+		}
+	}
+}
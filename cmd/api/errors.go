@@ -1,12 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
-	"strings"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"apiapp/internal/request"
 	"apiapp/internal/response"
 	"apiapp/internal/validator"
 )
@@ -28,8 +33,11 @@ func (app *application) reportServerError(r *http.Request, err error) {
 // errorMessage генерирует JSON-ответ с сообщением об ошибке и необязательными заголовками, логгирует ошибку
 // и устанавливает соответствующий HTTP-статус код.
 func (app *application) errorMessage(w http.ResponseWriter, r *http.Request, status int, message string, headers http.Header) {
-	// Заглавная буква первого символа сообщения об ошибке.
-	message = strings.ToUpper(message[:1]) + message[1:]
+	// Заглавная буква первого символа сообщения об ошибке. Первый символ берется как руна,
+	// чтобы не повредить многобайтовые символы UTF-8, например кириллицу.
+	if first, size := utf8.DecodeRuneInString(message); size > 0 {
+		message = string(unicode.ToUpper(first)) + message[size:]
+	}
 
 	// Генерация JSON-ответа с сообщением об ошибке и заголовками.
 	err := response.JSONWithHeaders(w, status, map[string]string{"Error": message}, headers)
@@ -69,6 +77,38 @@ func (app *application) badRequest(w http.ResponseWriter, r *http.Request, err e
 	app.errorMessage(w, r, http.StatusBadRequest, err.Error(), nil)
 }
 
+// unsupportedMediaType обрабатывает запросы с неподдерживаемым типом содержимого тела, предоставляя ответ 415 Unsupported Media Type.
+func (app *application) unsupportedMediaType(w http.ResponseWriter, r *http.Request, err error) {
+	// Генерация ответа с сообщением об ошибке.
+	app.errorMessage(w, r, http.StatusUnsupportedMediaType, err.Error(), nil)
+}
+
+// decodeError выбирает ответ для ошибки декодирования тела запроса: 415 для неподдерживаемого типа содержимого,
+// 422 для операций патча, которые не удалось применить, и 400 для всех остальных ошибок.
+func (app *application) decodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var unsupportedMediaTypeError *request.UnsupportedMediaTypeError
+	var patchError *request.PatchError
+
+	switch {
+	case errors.As(err, &unsupportedMediaTypeError):
+		app.unsupportedMediaType(w, r, err)
+
+	case errors.As(err, &patchError):
+		// Ошибка операции патча сообщается как ошибка поля с ключом в виде JSON-указателя.
+		code := "patch_failed"
+		if errors.Is(err, request.ErrPatchTestFailed) {
+			code = "patch_test_failed"
+		}
+
+		var v validator.Validator
+		v.AddFieldDetail(patchError.Path, validator.FieldError{Code: code, Message: patchError.Error()})
+		app.failedValidation(w, r, v)
+
+	default:
+		app.badRequest(w, r, err)
+	}
+}
+
 // failedValidation обрабатывает запросы с ошибками валидации, предоставляя ответ 422 Unprocessable Entity.
 func (app *application) failedValidation(w http.ResponseWriter, r *http.Request, v validator.Validator) {
 	// Генерация JSON-ответа с ошибками валидации.
@@ -79,6 +119,17 @@ func (app *application) failedValidation(w http.ResponseWriter, r *http.Request,
 	}
 }
 
+// serviceUnavailable отправляет ответ 503 Service Unavailable с сообщением и заголовком Retry-After
+// (в секундах), если задано время повторной попытки.
+func (app *application) serviceUnavailable(w http.ResponseWriter, r *http.Request, message string, retryAfter time.Duration) {
+	headers := make(http.Header)
+	if retryAfter > 0 {
+		headers.Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)))
+	}
+
+	app.errorMessage(w, r, http.StatusServiceUnavailable, message, headers)
+}
+
 // basicAuthenticationRequired обрабатывает запросы, требующие базовой аутентификации, но не содержащие действительных учетных данных.
 // Предоставляет ответ 401 Unauthorized с необходимыми заголовками для базовой аутентификации.
 func (app *application) basicAuthenticationRequired(w http.ResponseWriter, r *http.Request) {
@@ -3,18 +3,52 @@ package main
 import (
 	"net/http"
 
+	"apiapp/internal/health"
 	"apiapp/internal/response"
+	"apiapp/internal/version"
 )
 
-// status обрабатывает запрос к эндпоинту /status, возвращая JSON-ответ с текущим статусом "OK".
-func (app *application) status(w http.ResponseWriter, r *http.Request) {
-	// Создание карты данных для JSON-ответа.
-	data := map[string]string{
-		"Status": "OK",
+// livez обрабатывает запрос к эндпоинту /livez, возвращая результат liveness-проверок.
+func (app *application) livez(w http.ResponseWriter, r *http.Request) {
+	app.healthReport(w, r, app.health.Liveness(r.Context()))
+}
+
+// readyz обрабатывает запросы к эндпоинтам /readyz и /status, возвращая результат readiness-проверок.
+// После начала Graceful Shutdown всегда возвращает 503.
+func (app *application) readyz(w http.ResponseWriter, r *http.Request) {
+	app.healthReport(w, r, app.health.Readiness(r.Context()))
+}
+
+// versionInfo обрабатывает запрос к эндпоинту /version, возвращая сведения о версии и сборке приложения.
+func (app *application) versionInfo(w http.ResponseWriter, r *http.Request) {
+	err := response.JSON(w, http.StatusOK, version.Read())
+	if err != nil {
+		app.serverError(w, r, err)
+	}
+}
+
+// healthReport отправляет отчет о состоянии: 200 (OK) или 503 (Service Unavailable), если статус failing.
+// Результаты отдельных проверок включаются в ответ только для аутентифицированных запросов,
+// чтобы не раскрывать внутреннее устройство сервиса.
+func (app *application) healthReport(w http.ResponseWriter, r *http.Request, report health.Report) {
+	// Определение кода статуса по итоговому статусу отчета.
+	status := http.StatusOK
+	if report.Failing() {
+		status = http.StatusServiceUnavailable
+	}
+
+	// Скрытие деталей проверок для неаутентифицированных запросов.
+	ok, err := app.authenticated(r)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	if !ok {
+		report.Checks = nil
 	}
 
-	// Генерация JSON-ответа с данными и кодом статуса 200 (OK).
-	err := response.JSON(w, http.StatusOK, data)
+	// Генерация JSON-ответа с отчетом.
+	err = response.JSON(w, status, report)
 	if err != nil {
 		// Если произошла ошибка при генерации JSON-ответа, вызываем обработчик серверной ошибки.
 		app.serverError(w, r, err)
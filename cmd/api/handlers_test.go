@@ -1,15 +1,33 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"apiapp/internal/health"
+	"apiapp/internal/jobs"
+	"apiapp/internal/schema"
+	"apiapp/internal/settings"
+	"apiapp/internal/version"
+
+	"github.com/gorilla/mux"
 )
 
-// Тестирование функции status.
-func TestStatus(t *testing.T) {
+// Тестирование функции readyz.
+func TestReadyz(t *testing.T) {
 	// Создание экземпляра приложения для теста.
-	app := &application{}
+	app := &application{health: health.New(time.Second)}
 
 	// Создание HTTP-запроса.
 	req := httptest.NewRequest("GET", "/status", nil)
@@ -17,15 +35,59 @@ func TestStatus(t *testing.T) {
 	// Создание записи для записи HTTP-ответа.
 	w := httptest.NewRecorder()
 
-	// Вызов функции status с записью ответа.
-	app.status(w, req)
+	// Вызов функции readyz с записью ответа.
+	app.readyz(w, req)
 
 	// Проверка кода статуса ответа.
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 
-	// TODO: Проверка тела ответа и других ожидаемых результатов.
+	// После начала завершения работы проверка готовности должна возвращать 503.
+	app.health.SetShuttingDown()
+	w = httptest.NewRecorder()
+	app.readyz(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+// Тестирование функции livez с критичной и некритичной проверками.
+func TestLivez(t *testing.T) {
+	// Создание экземпляра приложения с некритичной неуспешной проверкой.
+	app := &application{health: health.New(time.Second)}
+	app.health.Register(health.Check{
+		Name:     "cache",
+		Func:     func(context.Context) error { return errors.New("unavailable") },
+		Liveness: true,
+	})
+
+	req := httptest.NewRequest("GET", "/livez", nil)
+	w := httptest.NewRecorder()
+	app.livez(w, req)
+
+	// Неуспешная некритичная проверка дает статус degraded, но не 503.
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"degraded"`) {
+		t.Errorf("Expected degraded status with code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Детали проверок не раскрываются без аутентификации.
+	if strings.Contains(w.Body.String(), "unavailable") {
+		t.Errorf("Expected check details to be hidden, got %s", w.Body.String())
+	}
+
+	// Неуспешная критичная проверка дает 503.
+	app.health.Register(health.Check{
+		Name:     "database",
+		Func:     func(context.Context) error { return errors.New("down") },
+		Critical: true,
+		Liveness: true,
+	})
+	w = httptest.NewRecorder()
+	app.livez(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
 }
 
 // Тестирование функции protected.
@@ -49,3 +111,243 @@ func TestProtected(t *testing.T) {
 
 	// TODO: Проверка тела ответа и других ожидаемых результатов.
 }
+
+// Тестирование ответа в режиме обслуживания.
+func TestCheckMaintenance(t *testing.T) {
+	// Создание экземпляра приложения во включенном режиме обслуживания.
+	app := &application{health: health.New(time.Second), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	app.setMaintenance(maintenanceMode{Enabled: true, Message: "сервис на обслуживании", RetryAfter: 2 * time.Minute})
+
+	handler := app.checkMaintenance(http.HandlerFunc(app.protected))
+
+	// Обычные маршруты возвращают 503 с заголовком Retry-After и сообщением с заглавной буквы.
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/protected", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "120" {
+		t.Errorf("Expected Retry-After 120, got %q", got)
+	}
+	if !strings.Contains(w.Body.String(), "Сервис на обслуживании") {
+		t.Errorf("Expected capitalized message, got %s", w.Body.String())
+	}
+	if n := app.inFlight.Load(); n != 0 {
+		t.Errorf("Expected rejected request not to be counted, got %d in flight", n)
+	}
+
+	// Эндпоинты проверки состояния остаются доступными.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// После выключения режима запросы обрабатываются как обычно.
+	app.setMaintenance(maintenanceMode{})
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/protected", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Обрабатываемый запрос учитывается до завершения обработчика.
+	var inFlight int64
+	handler = app.checkMaintenance(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = app.inFlight.Load()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/protected", nil))
+	if inFlight != 1 || app.inFlight.Load() != 0 {
+		t.Errorf("Expected 1 request in flight during the handler and 0 after, got %d and %d", inFlight, app.inFlight.Load())
+	}
+}
+
+// Тестирование переключения режима обслуживания при перезагрузке конфигурации.
+func TestMaintenanceReload(t *testing.T) {
+	var next config
+	cfg := &config{}
+	app := &application{health: health.New(0), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	app.settings = settings.NewReloader(cfg, func() (*config, error) {
+		cfg := next
+		return &cfg, nil
+	})
+	app.subscribeConfig()
+
+	// Изменение сообщения не отменяет режим, включенный через служебный сервер.
+	app.setMaintenance(maintenanceMode{Enabled: true, Message: "обновление"})
+	next.Maintenance.Message = "плановые работы"
+	app.reloadConfig()
+	if mode := app.currentMaintenance(); !mode.Enabled || mode.Message != "обновление" {
+		t.Errorf("Expected maintenance mode set through the admin server to be kept, got %+v", mode)
+	}
+
+	// Изменение MAINTENANCE_MODE переключает режим с параметрами из конфигурации.
+	next.Maintenance.Enabled = true
+	app.setMaintenance(maintenanceMode{})
+	app.reloadConfig()
+	if mode := app.currentMaintenance(); !mode.Enabled || mode.Message != "плановые работы" {
+		t.Errorf("Expected maintenance mode from the configuration, got %+v", mode)
+	}
+
+	next.Maintenance.Enabled = false
+	app.reloadConfig()
+	if mode := app.currentMaintenance(); mode.Enabled {
+		t.Errorf("Expected maintenance mode to be off, got %+v", mode)
+	}
+}
+
+// Тестирование функции versionInfo.
+func TestVersionInfo(t *testing.T) {
+	app := &application{}
+
+	req := httptest.NewRequest("GET", "/version", nil)
+	w := httptest.NewRecorder()
+	app.versionInfo(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var info version.Info
+	err := json.NewDecoder(w.Body).Decode(&info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != version.Get() || info.GoVersion != runtime.Version() {
+		t.Errorf("Unexpected version info: %+v", info)
+	}
+}
+
+// Тестирование просмотра и повторной постановки в очередь неудавшихся фоновых задач.
+func TestAdminDeadLetters(t *testing.T) {
+	app := &application{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	app.jobs = jobs.New(jobs.Options{
+		QueueSize:      1,
+		Retry:          jobs.RetryPolicy{MaxAttempts: 2},
+		DeadLetterSize: 10,
+		OnError:        app.reportJobError,
+	})
+	defer app.jobs.Shutdown(context.Background())
+
+	// Фоновая задача, завершающаяся ошибкой при каждой попытке. Она выполняется один раз, несмотря на
+	// политику повторных попыток пула.
+	var calls atomic.Int64
+	app.backgroundTask(httptest.NewRequest("POST", "/signup", nil), func() error {
+		calls.Add(1)
+		return errors.New("smtp unavailable")
+	})
+	for app.jobs.DeadLetters().Len() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	app.adminDeadLetters(w, httptest.NewRequest("GET", "/jobs/dead-letters", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name": "POST /signup"`) {
+		t.Fatalf("Unexpected response %d: %s", w.Code, w.Body.String())
+	}
+
+	// Подробности задачи содержат единственную попытку.
+	id := app.jobs.DeadLetters().List()[0].ID
+	w = httptest.NewRecorder()
+	app.adminDeadLetter(w, mux.SetURLVars(httptest.NewRequest("GET", "/jobs/dead-letters/"+id, nil), map[string]string{"id": id}))
+
+	var dl jobs.DeadLetter
+	err := json.NewDecoder(w.Body).Decode(&dl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dl.Attempts) != 1 || dl.Attempts[0].Error != "smtp unavailable" || calls.Load() != 1 {
+		t.Errorf("Unexpected dead letter: %+v", dl)
+	}
+
+	// Повторная постановка в очередь выполняет задачу заново.
+	w = httptest.NewRecorder()
+	app.adminRetryDeadLetter(w, mux.SetURLVars(httptest.NewRequest("POST", "/jobs/dead-letters/"+id+"/retry", nil), map[string]string{"id": id}))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected status code %d, got %d", http.StatusAccepted, w.Code)
+	}
+	for calls.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Неизвестная задача.
+	w = httptest.NewRecorder()
+	app.adminRetryDeadLetter(w, mux.SetURLVars(httptest.NewRequest("POST", "/jobs/dead-letters/"+id+"/retry", nil), map[string]string{"id": id}))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// Тестирование вывода действующей конфигурации служебным сервером.
+func TestAdminConfig(t *testing.T) {
+	t.Setenv("HTTP_PORT", "5555")
+
+	var cfg config
+	err := loadConfig(&cfg, []settings.Source{settings.Env{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	app := &application{settings: settings.NewReloader(&cfg, nil)}
+
+	w := httptest.NewRecorder()
+	app.adminConfig(w, httptest.NewRequest("GET", "/config", nil))
+
+	var values []settings.Value
+	err = json.NewDecoder(w.Body).Decode(&values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]settings.Value{}
+	for _, value := range values {
+		got[value.Key] = value
+	}
+
+	tests := []settings.Value{
+		{Key: "HTTP_PORT", Value: "5555", Source: "env"},
+		{Key: "JOBS_WORKERS", Value: "4", Source: "default"},
+		{Key: "BASIC_AUTH_HASHED_PASSWORD", Value: settings.Redacted, Source: "default"},
+		{Key: "ADMIN_ADDR"},
+	}
+
+	for _, tt := range tests {
+		if got[tt.Key] != tt {
+			t.Errorf("expected %+v, got %+v", tt, got[tt.Key])
+		}
+	}
+}
+
+// Тестирование проверки тела запроса по JSON Schema.
+func TestValidateSchema(t *testing.T) {
+	registry, err := schema.Load(fstest.MapFS{"ids.json": {Data: []byte(`{
+		"type": "object",
+		"required": ["id"],
+		"properties": {"id": {"type": "integer", "maximum": 9007199254740992}}
+	}`)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	app := &application{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	var received string
+	handler := app.validateSchema(registry.MustGet("ids"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// Корректное тело передается обработчику без изменений.
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/ids", strings.NewReader(`{"id": 9007199254740992}`)))
+	if w.Code != http.StatusNoContent || received != `{"id": 9007199254740992}` {
+		t.Errorf("Expected status code %d and the original body, got %d and %q", http.StatusNoContent, w.Code, received)
+	}
+
+	// Число, превышающее maximum на единицу, не теряет точность при проверке.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/ids", strings.NewReader(`{"id": 9007199254740993}`)))
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "/id") {
+		t.Errorf("Expected status code %d with an error for /id, got %d: %s", http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	}
+}
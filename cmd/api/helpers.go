@@ -1,36 +1,149 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
+
+	"apiapp/internal/jobs"
+	"apiapp/internal/settings"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
-// backgroundTask запускает фоновую задачу в виде горутины, ожидая её завершения.
-// Принимает объект запроса `r` и функцию `fn`, которая выполняется в фоновой горутине.
-// Использует sync.WaitGroup, чтобы отслеживать выполнение фоновой задачи.
+// backgroundTask ставит фоновую задачу в пул воркеров app.jobs. Принимает объект запроса `r`, по которому
+// задача называется в логах, и функцию `fn`, которая выполняется в одном из воркеров пула. Функция выполняется
+// один раз, так как произвольная логика не обязательно допускает повторное выполнение. Задача, завершившаяся
+// ошибкой или паникой, логгируется reportJobError и сохраняется в хранилище неудавшихся задач.
+//
+// Постановка в очередь использует контекст запроса r.Context(). Если очередь заполнена, обработчик ждет
+// освобождения места не дольше JOBS_ENQUEUE_TIMEOUT, а если клиент за это время отключится, задача
+// отбрасывается. Если пул не принял задачу, ошибка логгируется, а задача не выполняется.
+//
+// Задачи, которые допускают повторное выполнение, следует ставить напрямую через app.jobs.Submit как jobs.Job
+// с функцией, принимающей контекст. Так же ставятся задачи, которые должны учитывать тайм-аут и завершение
+// работы или задавать свою политику повторных попыток.
 func (app *application) backgroundTask(r *http.Request, fn func() error) {
-	// Увеличение счетчика ожидаемых горутин в sync.WaitGroup.
-	app.wg.Add(1)
-
-	// Запуск новой горутины.
-	go func() {
-		// Уменьшение счетчика при завершении горутины (независимо от результата выполнения).
-		defer app.wg.Done()
-
-		// Защита от паники в горутине.
-		defer func() {
-			err := recover()
-			if err != nil {
-				// В случае паники логгируется информация об ошибке.
-				app.reportServerError(r, fmt.Errorf("%s", err))
-			}
-		}()
+	job := jobs.Job{
+		Name:  r.Method + " " + r.URL.Path,
+		Retry: &jobs.RetryPolicy{MaxAttempts: 1},
+		Func: func(ctx context.Context) error {
+			return fn()
+		},
+	}
+
+	err := app.jobs.Submit(r.Context(), job)
+	if err != nil {
+		app.reportServerError(r, fmt.Errorf("background task not started: %w", err))
+	}
+}
+
+// reportJobRetry логгирует неудачную попытку фоновой задачи, после которой задача будет повторена.
+func (app *application) reportJobRetry(job jobs.Job, attempt int, delay time.Duration, err error) {
+	app.logger.Warn("background job failed, retrying", "job", job.Name, "attempt", attempt, "delay", delay, "error", err)
+}
+
+// reportJobError логгирует ошибку фоновой задачи после последней попытки. Для паники в лог добавляется
+// стек вызовов горутины задачи.
+func (app *application) reportJobError(job jobs.Job, attempts int, err error) {
+	attrs := []any{"job", job.Name, "attempts", attempts}
+
+	var panicErr *jobs.PanicError
+	if errors.As(err, &panicErr) {
+		attrs = append(attrs, "trace", panicErr.Stack)
+	}
+
+	app.logger.Error(err.Error(), attrs...)
+}
+
+// maintenanceMode описывает состояние режима обслуживания. Состояние заменяется целиком
+// через atomic.Pointer, поэтому его переключение безопасно при параллельных запросах.
+type maintenanceMode struct {
+	Enabled    bool
+	Message    string
+	RetryAfter time.Duration
+	Since      time.Time
+}
+
+// setMaintenance устанавливает режим обслуживания и синхронизирует с ним проверку готовности.
+func (app *application) setMaintenance(mode maintenanceMode) {
+	if mode.Enabled && mode.Since.IsZero() {
+		mode.Since = time.Now()
+	}
+
+	app.maintenance.Store(&mode)
+	app.health.SetMaintenance(mode.Enabled)
 
-		// Выполнение функции в фоновой горутине и обработка возможных ошибок.
-		err := fn()
-		if err != nil {
-			// В случае ошибки логгируется информация об ошибке.
-			app.reportServerError(r, err)
+	app.logger.Info("maintenance mode changed", "enabled", mode.Enabled)
+}
+
+// currentMaintenance возвращает текущее состояние режима обслуживания.
+func (app *application) currentMaintenance() maintenanceMode {
+	mode := app.maintenance.Load()
+	if mode == nil {
+		return maintenanceMode{}
+	}
+	return *mode
+}
+
+// reloadConfig повторно загружает конфигурацию из всех источников и применяет ее, если она корректна
+// и не меняет параметры, требующие перезапуска. Изменения записываются в лог, секреты не раскрываются.
+func (app *application) reloadConfig() {
+	changes, err := app.settings.Reload()
+	if err != nil {
+		// Если изменения отклонены из-за параметров, требующих перезапуска, они записываются в лог.
+		for _, change := range changes {
+			if change.Restart {
+				app.logger.Warn("configuration change requires a restart", "key", change.Key, "old", change.Old, "new", change.New)
+			}
 		}
-	}()
+		app.logger.Error("configuration reload rejected", "error", err)
+		return
+	}
+
+	for _, change := range changes {
+		app.logger.Info("configuration changed", "key", change.Key, "old", change.Old, "new", change.New)
+	}
+
+	app.logger.Info("configuration reloaded", "changes", len(changes))
+}
+
+// subscribeConfig подписывает компоненты приложения на изменения параметров конфигурации.
+// Параметры, которые читаются через app.config() при каждом запросе (например, учетные данные
+// базовой аутентификации), подписки не требуют.
+func (app *application) subscribeConfig() {
+	settings.Subscribe(app.settings, func(cfg *config) slog.Level { return cfg.LogLevel }, func(level slog.Level) {
+		app.logLevel.Set(level)
+		app.logger.Info("log level changed", "level", level.String())
+	})
+
+	// Режим обслуживания переключается только при изменении MAINTENANCE_MODE, чтобы изменение сообщения или
+	// времени повторной попытки не отменяло состояние, заданное через служебный сервер.
+	settings.Subscribe(app.settings, func(cfg *config) bool { return cfg.Maintenance.Enabled }, func(enabled bool) {
+		app.logger.Info("maintenance mode changed by configuration", "enabled", enabled)
+		app.applyMaintenanceConfig(app.config().Maintenance)
+	})
+}
+
+// applyMaintenanceConfig включает или выключает режим обслуживания по параметрам конфигурации.
+func (app *application) applyMaintenanceConfig(cfg maintenanceConfig) {
+	if !cfg.Enabled {
+		app.setMaintenance(maintenanceMode{})
+		return
+	}
+
+	app.setMaintenance(maintenanceMode{
+		Enabled:    true,
+		Message:    cfg.Message,
+		RetryAfter: cfg.RetryAfter,
+	})
+}
+
+// isBcryptHash проверяет, что значение является хешем bcrypt.
+func isBcryptHash(hash string) bool {
+	_, err := bcrypt.Cost([]byte(hash))
+	return err == nil
 }
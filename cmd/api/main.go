@@ -2,26 +2,61 @@
 package main
 
 import (
-	"flag"
+	"errors"
+	"expvar"
 	"fmt"
 	"log/slog"
 	"os"
 	"runtime/debug"
-	"sync"
+	"strings"
+	"sync/atomic"
+	"time"
 
-	"apiapp/internal/env"
+	"apiapp/internal/health"
+	"apiapp/internal/jobs"
+	"apiapp/internal/secrets"
+	"apiapp/internal/settings"
+	"apiapp/internal/validator"
 	"apiapp/internal/version"
 
+	"github.com/gorilla/mux"
 	"github.com/lmittmann/tint"
 )
 
-// Функция main инициализирует логгер, запускает приложение и обрабатывает ошибки.
+// Константы для настройки проверок состояния и отслеживания изменений конфигурации.
+const (
+	defaultHealthCacheTTL      = 2 * time.Second  // Время, в течение которого результаты проверок состояния берутся из кэша.
+	defaultConfigWatchInterval = 10 * time.Second // Интервал проверки изменений файла конфигурации и файлов секретов.
+)
+
+// Функция main инициализирует логгер, выполняет команду из аргументов командной строки и обрабатывает ошибки.
 func main() {
-	// Инициализация логгера с цветным выводом и уровнем отладки.
-	logger := slog.New(tint.NewHandler(os.Stdout, &tint.Options{Level: slog.LevelDebug}))
+	// Инициализация логгера с цветным выводом и уровнем отладки. Уровень можно изменить во время работы
+	// через служебный сервер.
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(slog.LevelDebug)
+	logger := slog.New(tint.NewHandler(os.Stdout, &tint.Options{Level: logLevel}))
+
+	// Выполнение команды (по умолчанию - запуск сервера) и обработка возможных ошибок.
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, logger: logger, logLevel: logLevel}
+	err := c.run(os.Args[1:])
+
+	// Ошибки конфигурации выводятся в виде отчета без трассировки стека.
+	var configError *settings.Error
+	if errors.As(err, &configError) {
+		fmt.Fprintln(os.Stderr, configError)
+		os.Exit(2)
+	}
+
+	// Ошибки команд выводятся без трассировки стека с кодом завершения команды.
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		if exitErr.err != nil {
+			fmt.Fprintln(os.Stderr, exitErr)
+		}
+		os.Exit(exitErr.code)
+	}
 
-	// Запуск приложения и обработка возможных ошибок.
-	err := run(logger)
 	if err != nil {
 		// В случае ошибки логгирование вместе с трассировкой стека и завершение с ненулевым кодом статуса.
 		trace := string(debug.Stack())
@@ -31,47 +66,271 @@ func main() {
 }
 
 // Структура config содержит конфигурационные параметры для приложения.
+// Значения загружаются из переменных окружения по тегам env с учетом значений по умолчанию
+// из тегов default и проверяются правилами из тегов validate (см. пакет internal/settings).
+// Параметры с тегом restart:"true" используются только при запуске, и их нельзя изменить
+// перезагрузкой конфигурации.
 type config struct {
-	baseURL   string
-	httpPort  int
-	basicAuth struct {
-		username       string
-		hashedPassword string
+	BaseURL    string     `env:"BASE_URL" default:"http://localhost:4444" validate:"url"`
+	LogLevel   slog.Level `env:"LOG_LEVEL" default:"debug"`
+	HTTPPort   int        `env:"HTTP_PORT" default:"4444" restart:"true" validate:"between=1 65535"`
+	HTTPListen []string   `env:"HTTP_LISTEN" restart:"true"`
+	UnixSocket struct {
+		Mode  string `env:"MODE"`
+		Owner string `env:"OWNER"`
+	} `envPrefix:"UNIX_SOCKET_" restart:"true"`
+	MaxRequestBodyBytes int `env:"MAX_REQUEST_BODY_BYTES" default:"1048576" restart:"true" validate:"min=1"`
+	Server              struct {
+		IdleTimeout          time.Duration `env:"HTTP_IDLE_TIMEOUT" default:"1m"`
+		ReadTimeout          time.Duration `env:"HTTP_READ_TIMEOUT" default:"5s"`
+		ReadHeaderTimeout    time.Duration `env:"HTTP_READ_HEADER_TIMEOUT" default:"3s"`
+		WriteTimeout         time.Duration `env:"HTTP_WRITE_TIMEOUT" default:"10s"`
+		ShutdownPeriod       time.Duration `env:"HTTP_SHUTDOWN_PERIOD" default:"30s"`
+		MaxHeaderBytes       int           `env:"HTTP_MAX_HEADER_BYTES" default:"1048576" validate:"min=1"`
+		H2C                  bool          `env:"HTTP_H2C" default:"false"`
+		MaxConcurrentStreams int           `env:"HTTP2_MAX_CONCURRENT_STREAMS" default:"250" validate:"min=1"`
+	} `restart:"true"`
+	BasicAuth struct {
+		Username       string `env:"USERNAME" default:"admin" validate:"required"`
+		HashedPassword string `env:"HASHED_PASSWORD" default:"$2a$10$jRb2qniNcoCyQM23T59RfeEQUbgdAXfR6S0scynmKfJa5Gj3arGJa" secret:"true" validate:"required"`
+	} `envPrefix:"BASIC_AUTH_"`
+	Maintenance maintenanceConfig `envPrefix:"MAINTENANCE_"`
+	Admin       struct {
+		Addr           string `env:"ADDR" restart:"true"`
+		Username       string `env:"USERNAME" default:"admin"`
+		HashedPassword string `env:"HASHED_PASSWORD" secret:"true"`
+	} `envPrefix:"ADMIN_"`
+	Jobs struct {
+		Workers         int           `env:"WORKERS" default:"4" validate:"min=1"`
+		QueueSize       int           `env:"QUEUE_SIZE" default:"100" validate:"min=1"`
+		Overflow        jobs.Overflow `env:"OVERFLOW" default:"block"`
+		EnqueueTimeout  time.Duration `env:"ENQUEUE_TIMEOUT" default:"5s"`
+		Timeout         time.Duration `env:"TIMEOUT" default:"1m"`
+		MaxAttempts     int           `env:"MAX_ATTEMPTS" default:"3" validate:"min=1"`
+		RetryBackoff    time.Duration `env:"RETRY_BACKOFF" default:"1s"`
+		RetryMaxBackoff time.Duration `env:"RETRY_MAX_BACKOFF" default:"1m"`
+		RetryJitter     float64       `env:"RETRY_JITTER" default:"0.2" validate:"between=0 1"`
+		DeadLetterSize  int           `env:"DEAD_LETTER_SIZE" default:"1000" validate:"min=1"`
+	} `envPrefix:"JOBS_" restart:"true"`
+	// Источники, из которых загружена конфигурация, для вывода действующих значений служебным сервером.
+	sources []settings.Source
+}
+
+// Структура maintenanceConfig содержит параметры режима обслуживания, применяемые при запуске
+// и при перезагрузке конфигурации.
+type maintenanceConfig struct {
+	Enabled    bool          `env:"MODE" default:"false"`
+	Message    string        `env:"MESSAGE" default:"Сервис временно недоступен из-за технического обслуживания" validate:"notblank"`
+	RetryAfter time.Duration `env:"RETRY_AFTER" default:"1m"`
+}
+
+// Validate выполняет проверки конфигурации, которые нельзя выразить тегами validate.
+func (cfg config) Validate(v *validator.Validator) {
+	// Тайм-ауты не могут быть отрицательными.
+	v.CheckField(cfg.Server.IdleTimeout >= 0, "Server.IdleTimeout", "must not be negative")
+	v.CheckField(cfg.Server.ReadTimeout >= 0, "Server.ReadTimeout", "must not be negative")
+	v.CheckField(cfg.Server.ReadHeaderTimeout >= 0, "Server.ReadHeaderTimeout", "must not be negative")
+	v.CheckField(cfg.Server.WriteTimeout >= 0, "Server.WriteTimeout", "must not be negative")
+	v.CheckField(cfg.Server.ShutdownPeriod > 0, "Server.ShutdownPeriod", "must be greater than zero")
+	v.CheckField(cfg.Jobs.EnqueueTimeout >= 0, "Jobs.EnqueueTimeout", "must not be negative")
+	v.CheckField(cfg.Jobs.Timeout >= 0, "Jobs.Timeout", "must not be negative")
+	v.CheckField(cfg.Jobs.RetryBackoff >= 0, "Jobs.RetryBackoff", "must not be negative")
+	v.CheckField(cfg.Jobs.RetryMaxBackoff >= 0, "Jobs.RetryMaxBackoff", "must not be negative")
+
+	// Служебный сервер не запускается без отдельного пароля администратора.
+	if cfg.Admin.Addr != "" {
+		v.CheckField(cfg.Admin.Username != "", "Admin.Username", "must be provided when ADMIN_ADDR is set")
+		v.CheckField(cfg.Admin.HashedPassword != "", "Admin.HashedPassword", "must be provided when ADMIN_ADDR is set")
+	}
+
+	// Некорректный хеш пароля привел бы к ошибке 500 при каждой попытке аутентификации.
+	v.CheckField(isBcryptHash(cfg.BasicAuth.HashedPassword), "BasicAuth.HashedPassword", "must be a bcrypt hash")
+	if cfg.Admin.HashedPassword != "" {
+		v.CheckField(isBcryptHash(cfg.Admin.HashedPassword), "Admin.HashedPassword", "must be a bcrypt hash")
 	}
 }
 
-// Структура application инкапсулирует состояние приложения, включая конфигурацию, логгер и wait group.
+// Структура application инкапсулирует состояние приложения, включая конфигурацию, логгер и пул фоновых задач.
 type application struct {
-	config config
-	logger *slog.Logger
-	wg     sync.WaitGroup
+	settings    *settings.Reloader[config]
+	logger      *slog.Logger
+	logLevel    *slog.LevelVar
+	health      *health.Registry
+	maintenance atomic.Pointer[maintenanceMode]
+	inFlight    atomic.Int64
+	startedAt   time.Time
+	jobs        *jobs.Pool
+	watchPaths  []string // Файлы конфигурации и секретов, при изменении которых конфигурация перезагружается.
+
+	// Имена middleware маршрутизаторов для вывода командой routes.
+	middleware map[*mux.Router]*routerMiddleware
 }
 
-// Функция run инициализирует конфигурацию, парсит флаги командной строки и запускает HTTP-сервер.
-func run(logger *slog.Logger) error {
-	// Инициализация конфигурации с значениями по умолчанию или значениями из переменных окружения.
-	var cfg config
-	cfg.baseURL = env.GetString("BASE_URL", "http://localhost:4444")
-	cfg.httpPort = env.GetInt("HTTP_PORT", 4444)
-	cfg.basicAuth.username = env.GetString("BASIC_AUTH_USERNAME", "admin")
-	cfg.basicAuth.hashedPassword = env.GetString("BASIC_AUTH_HASHED_PASSWORD", "$2a$10$jRb2qniNcoCyQM23T59RfeEQUbgdAXfR6S0scynmKfJa5Gj3arGJa")
+// config возвращает текущую конфигурацию приложения. Конфигурация может быть заменена во время работы,
+// поэтому возвращаемое значение нельзя изменять и не следует сохранять надолго.
+func (app *application) config() *config {
+	if app.settings == nil {
+		return &config{}
+	}
+	return app.settings.Current()
+}
 
-	// Парсинг флагов командной строки, включая флаг для отображения версии.
-	showVersion := flag.Bool("version", false, "отобразить версию и завершить программу")
-	flag.Parse()
+// serve загружает конфигурацию, создает экземпляр приложения и запускает HTTP-сервер.
+func (c *cli) serve(args []string) error {
+	err := c.parseFlags(c.flagSet("serve"), args)
+	if err != nil {
+		return err
+	}
 
-	// Если установлен флаг версии, вывод версии и завершение без запуска приложения.
-	if *showVersion {
-		fmt.Printf("версия: %s\n", version.Get())
-		return nil
+	// Загрузка конфигурации с проверкой значений.
+	cfg, watchPaths, err := c.loadConfig()
+	if err != nil {
+		return err
 	}
 
 	// Создание экземпляра приложения с сконфигурированными значениями и логгером.
 	app := &application{
-		config: cfg,
-		logger: logger,
+		logger:    c.logger,
+		logLevel:  c.logLevel,
+		health:    health.New(defaultHealthCacheTTL),
+		startedAt: time.Now(),
 	}
 
+	// Конфигурация перезагружается из всех источников по сигналу SIGHUP и при изменении файла
+	// конфигурации или файлов секретов.
+	app.settings = settings.NewReloader(cfg, func() (*config, error) {
+		cfg, _, err := c.loadConfig()
+		return cfg, err
+	})
+	app.subscribeConfig()
+	app.watchPaths = watchPaths
+
+	// Применение начальных значений параметров, изменяемых во время работы.
+	c.logLevel.Set(cfg.LogLevel)
+	if cfg.Maintenance.Enabled {
+		app.applyMaintenanceConfig(cfg.Maintenance)
+	}
+
+	// Пул воркеров для фоновых задач. Его параметры применяются только при запуске.
+	app.jobs = jobs.New(jobs.Options{
+		Workers:        cfg.Jobs.Workers,
+		QueueSize:      cfg.Jobs.QueueSize,
+		Overflow:       cfg.Jobs.Overflow,
+		EnqueueTimeout: cfg.Jobs.EnqueueTimeout,
+		Timeout:        cfg.Jobs.Timeout,
+		Retry: jobs.RetryPolicy{
+			MaxAttempts: cfg.Jobs.MaxAttempts,
+			Backoff:     cfg.Jobs.RetryBackoff,
+			MaxBackoff:  cfg.Jobs.RetryMaxBackoff,
+			Jitter:      cfg.Jobs.RetryJitter,
+		},
+		DeadLetterSize: cfg.Jobs.DeadLetterSize,
+		OnRetry:        app.reportJobRetry,
+		OnError:        app.reportJobError,
+	})
+
+	// Публикация сведений о сборке и метрик фоновых задач в expvar, сведений о сборке - в логе запуска.
+	expvar.Publish("build", expvar.Func(func() any { return version.Read() }))
+	expvar.Publish("jobs", expvar.Func(func() any { return app.jobs.Stats() }))
+	c.logger.Info("starting application", "build", version.Read())
+
 	// Запуск обслуживания HTTP-запросов и обработка возможных ошибок.
 	return app.serveHTTP()
 }
+
+// loadConfig загружает конфигурацию из источников с проверкой значений и заполняет вычисляемые значения.
+func loadConfig(cfg *config, sources []settings.Source) error {
+	err := settings.Load(cfg, sources...)
+	if err != nil {
+		return err
+	}
+	cfg.sources = sources
+
+	// По умолчанию сервер слушает порт из HTTP_PORT на всех интерфейсах.
+	if len(cfg.HTTPListen) == 0 {
+		cfg.HTTPListen = []string{fmt.Sprintf(":%d", cfg.HTTPPort)}
+	}
+
+	return nil
+}
+
+// configSources возвращает источники конфигурации в порядке возрастания приоритета:
+//   - файл конфигурации (путь из флага -config или переменной CONFIG_FILE);
+//   - зашифрованный файл секретов (SECRETS_FILE, расшифровывается мастер-ключом из SECRETS_MASTER_KEY);
+//   - каталог секретов (SECRETS_DIR);
+//   - переменные окружения;
+//   - файлы секретов из переменных <KEY>_FILE;
+//   - флаги командной строки.
+//
+// Также возвращает пути к файлу конфигурации и файлам секретов для отслеживания изменений.
+func configSources(configFile string, flags *settings.Flags) ([]settings.Source, []string, error) {
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+
+	var sources []settings.Source
+	var watchPaths []string
+
+	if configFile != "" {
+		file, err := settings.LoadFile(configFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, file)
+		watchPaths = append(watchPaths, configFile)
+	}
+
+	if path := os.Getenv("SECRETS_FILE"); path != "" {
+		masterKey, err := secretsMasterKey()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		store, err := secrets.FromEncryptedFile(path, masterKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, store)
+		watchPaths = append(watchPaths, store.Paths()...)
+	}
+
+	if dir := os.Getenv("SECRETS_DIR"); dir != "" {
+		store, err := secrets.FromDir(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, store)
+		watchPaths = append(watchPaths, store.Paths()...)
+	}
+
+	var keys []string
+	for _, info := range settings.Fields(&config{}) {
+		keys = append(keys, info.Key)
+	}
+
+	fileRefs, err := secrets.FromFileRefs(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	watchPaths = append(watchPaths, fileRefs.Paths()...)
+
+	return append(sources, settings.Env{}, fileRefs, flags), watchPaths, nil
+}
+
+// secretsMasterKey возвращает мастер-ключ файла секретов из переменной SECRETS_MASTER_KEY
+// или из файла, путь к которому задан в SECRETS_MASTER_KEY_FILE.
+func secretsMasterKey() (string, error) {
+	if path := os.Getenv("SECRETS_MASTER_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	masterKey := os.Getenv("SECRETS_MASTER_KEY")
+	if masterKey == "" {
+		return "", errors.New("SECRETS_MASTER_KEY or SECRETS_MASTER_KEY_FILE must be set")
+	}
+	return masterKey, nil
+}
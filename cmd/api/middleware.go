@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 
+	"apiapp/internal/request"
+	"apiapp/internal/schema"
+	"apiapp/internal/validator"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -25,40 +32,156 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 	})
 }
 
+// limitRequestBody возвращает middleware, устанавливающее предел размера тела запроса в n байт.
+// Используется для всего маршрутизатора с пределом из конфигурации и может быть переопределено для отдельных маршрутов,
+// например для эндпоинтов массового импорта.
+func (app *application) limitRequestBody(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Сохранение предела в контексте запроса для функций декодирования из пакета request.
+			next.ServeHTTP(w, request.WithMaxBytes(r, n))
+		})
+	}
+}
+
+// validateSchema возвращает middleware, проверяющее JSON-тело запроса по схеме s.
+// При нарушениях схемы отправляется ответ 422 с ошибками полей по JSON-указателям,
+// иначе тело восстанавливается, и обработчик может декодировать его с помощью request.DecodeJSON.
+func (app *application) validateSchema(s *schema.Schema) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Копирование прочитанного тела в буфер, чтобы передать его обработчику.
+			var body bytes.Buffer
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(r.Body, &body), r.Body}
+
+			// Тело читается как json.RawMessage, а затем проверяется с числами в виде json.Number,
+			// чтобы большие целые числа не теряли точность при проверке ограничений схемы.
+			var doc json.RawMessage
+			err := request.DecodeJSON(w, r, &doc)
+			if err != nil {
+				app.decodeError(w, r, err)
+				return
+			}
+
+			var v validator.Validator
+			err = s.ValidateJSON(doc, &v)
+			if err != nil {
+				app.serverError(w, r, err)
+				return
+			}
+			if v.HasErrors() {
+				app.failedValidation(w, r, v)
+				return
+			}
+
+			// Восстановление тела запроса для следующего обработчика.
+			r.Body = io.NopCloser(&body)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkMaintenance возвращает middleware, отклоняющее запросы с кодом 503 (Service Unavailable)
+// в режиме обслуживания. Эндпоинты проверки состояния остаются доступными, а запросы,
+// начатые до включения режима, завершаются как обычно. Middleware также ведет счетчик
+// выполняющихся запросов, по которому эндпоинт /drain служебного сервера ожидает их завершения.
+func (app *application) checkMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Эндпоинты проверки состояния и версии не учитываются и не блокируются.
+		switch r.URL.Path {
+		case "/livez", "/readyz", "/status", "/version":
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Учет выполняющегося запроса. Запрос учитывается до проверки режима обслуживания, чтобы ожидание
+		// завершения запросов после включения режима не пропустило запрос, уже прошедший проверку.
+		app.inFlight.Add(1)
+		defer app.inFlight.Add(-1)
+
+		// Отклонение запроса, если включен режим обслуживания.
+		mode := app.currentMaintenance()
+		if mode.Enabled {
+			app.serviceUnavailable(w, r, mode.Message, mode.RetryAfter)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // requireBasicAuthentication возвращает middleware, проверяющее наличие базовой аутентификации в запросе.
-// Проверяет корректность учетных данных, в том числе сравнивает хеш пароля.
 // В случае ошибки или несоответствия требованиям, вызывает соответствующий хендлер.
 func (app *application) requireBasicAuthentication(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Получение учетных данных из заголовков базовой аутентификации.
-		username, plaintextPassword, ok := r.BasicAuth()
+		// Проверка учетных данных запроса.
+		ok, err := app.authenticated(r)
+		if err != nil {
+			// В случае ошибки сравнения хешей вызывается хендлер серверной ошибки.
+			app.serverError(w, r, err)
+			return
+		}
 		if !ok {
-			// Если учетные данные отсутствуют, вызывается хендлер требования базовой аутентификации.
+			// Если учетные данные отсутствуют или неверны, вызывается хендлер требования базовой аутентификации.
 			app.basicAuthenticationRequired(w, r)
 			return
 		}
 
-		// Проверка соответствия имени пользователя требуемому значению.
-		if app.config.basicAuth.username != username {
-			// В случае несоответствия вызывается хендлер требования базовой аутентификации.
-			app.basicAuthenticationRequired(w, r)
+		// Если все проверки успешны, вызывается следующий хендлер в цепочке.
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticated проверяет учетные данные базовой аутентификации запроса по данным из конфигурации.
+// Возвращает false без ошибки, если учетные данные отсутствуют или не совпадают.
+func (app *application) authenticated(r *http.Request) (bool, error) {
+	return checkCredentials(r, app.config().BasicAuth.Username, app.config().BasicAuth.HashedPassword)
+}
+
+// requireAdminAuthentication возвращает middleware, проверяющее учетные данные администратора
+// для эндпоинтов служебного сервера.
+func (app *application) requireAdminAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверка учетных данных запроса по отдельным учетным данным администратора.
+		ok, err := checkCredentials(r, app.config().Admin.Username, app.config().Admin.HashedPassword)
+		if err != nil {
+			app.serverError(w, r, err)
 			return
 		}
-
-		// Сравнение хеша пароля с переданным паролем.
-		err := bcrypt.CompareHashAndPassword([]byte(app.config.basicAuth.hashedPassword), []byte(plaintextPassword))
-		switch {
-		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
-			// В случае несоответствия вызывается хендлер требования базовой аутентификации.
+		if !ok {
 			app.basicAuthenticationRequired(w, r)
 			return
-		case err != nil:
-			// В случае других ошибок вызывается хендлер серверной ошибки.
-			app.serverError(w, r, err)
-			return
 		}
 
-		// Если все проверки успешны, вызывается следующий хендлер в цепочке.
 		next.ServeHTTP(w, r)
 	})
 }
+
+// checkCredentials сравнивает учетные данные базовой аутентификации запроса с именем пользователя
+// и bcrypt-хешем пароля. Возвращает false без ошибки, если учетные данные отсутствуют или не совпадают.
+func checkCredentials(r *http.Request, username, hashedPassword string) (bool, error) {
+	// Получение учетных данных из заголовков базовой аутентификации.
+	requestUsername, plaintextPassword, ok := r.BasicAuth()
+	if !ok {
+		return false, nil
+	}
+
+	// Проверка соответствия имени пользователя требуемому значению.
+	if username != requestUsername {
+		return false, nil
+	}
+
+	// Сравнение хеша пароля с переданным паролем.
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plaintextPassword))
+	switch {
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	return true, nil
+}
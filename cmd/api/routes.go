@@ -17,17 +17,67 @@ func (app *application) routes() http.Handler {
 	mux.MethodNotAllowedHandler = http.HandlerFunc(app.methodNotAllowed)
 
 	// Использование middleware для восстановления от паник в обработчиках.
-	mux.Use(app.recoverPanic)
+	app.use(mux, app.recoverPanic)
 
-	// Установка обработчика для маршрута "/status" с методом GET.
-	mux.HandleFunc("/status", app.status).Methods("GET")
+	// Использование middleware, отклоняющего запросы в режиме обслуживания.
+	app.use(mux, app.checkMaintenance)
+
+	// Использование middleware для ограничения размера тела запроса значением из конфигурации.
+	app.use(mux, app.limitRequestBody(int64(app.config().MaxRequestBodyBytes)))
+
+	// Установка обработчиков проверки состояния. Маршрут "/status" сохранен для совместимости и равнозначен "/readyz".
+	mux.HandleFunc("/livez", app.livez).Methods("GET")
+	mux.HandleFunc("/readyz", app.readyz).Methods("GET")
+	mux.HandleFunc("/status", app.readyz).Methods("GET")
+
+	// Установка обработчика со сведениями о версии и сборке приложения.
+	mux.HandleFunc("/version", app.versionInfo).Methods("GET")
 
 	// Создание подмаршрута для защищенных ресурсов, используя middleware для базовой аутентификации.
-	protectedRoutes := mux.NewRoute().Subrouter()
-	protectedRoutes.Use(app.requireBasicAuthentication)
+	protectedRoutes := app.subrouter(mux)
+	app.use(protectedRoutes, app.requireBasicAuthentication)
 	// Установка обработчика для защищенного маршрута "/basic-auth-protected" с методом GET.
 	protectedRoutes.HandleFunc("/basic-auth-protected", app.protected).Methods("GET")
 
 	// Возврат маршрутизатора как HTTP-обработчика.
 	return mux
 }
+
+// routerMiddleware описывает middleware, подключенные к маршрутизатору, для вывода командой routes.
+type routerMiddleware struct {
+	parent *mux.Router // Родительский маршрутизатор для подмаршрутов.
+	names  []string
+}
+
+// use подключает middleware к маршрутизатору и запоминает их имена.
+func (app *application) use(router *mux.Router, middleware ...mux.MiddlewareFunc) {
+	if app.middleware == nil {
+		app.middleware = make(map[*mux.Router]*routerMiddleware)
+	}
+	if app.middleware[router] == nil {
+		app.middleware[router] = &routerMiddleware{}
+	}
+
+	for _, mw := range middleware {
+		router.Use(mw)
+		app.middleware[router].names = append(app.middleware[router].names, funcName(mw))
+	}
+}
+
+// subrouter создает подмаршрут, наследующий middleware родительского маршрутизатора.
+func (app *application) subrouter(parent *mux.Router) *mux.Router {
+	router := parent.NewRoute().Subrouter()
+	app.use(router)
+	app.middleware[router].parent = parent
+	return router
+}
+
+// middlewareChain возвращает имена middleware, через которые проходят запросы к маршрутам
+// маршрутизатора router, в порядке их вызова.
+func (app *application) middlewareChain(router *mux.Router) []string {
+	info := app.middleware[router]
+	if info == nil {
+		return nil
+	}
+	return append(app.middlewareChain(info.parent), info.names...)
+}
@@ -5,59 +5,195 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
+
+	"apiapp/internal/secrets"
+	"apiapp/internal/systemd"
+	"apiapp/internal/upgrade"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
-// Константы для настройки тайм-аутов и периода завершения сервера.
+// Константы для настройки перезапуска и служебного сервера. Значения по умолчанию остальных
+// настроек сервера задаются в тегах default структуры config.
 const (
-	defaultIdleTimeout    = time.Minute      // Время ожидания бездействия до закрытия соединения.
-	defaultReadTimeout    = 5 * time.Second  // Время ожидания чтения данных из запроса.
-	defaultWriteTimeout   = 10 * time.Second // Время ожидания записи данных в ответ.
-	defaultShutdownPeriod = 30 * time.Second // Период завершения сервера при получении сигнала завершения.
+	defaultUpgradeTimeout    = 30 * time.Second // Время ожидания готовности нового процесса при перезапуске.
+	defaultAdminWriteTimeout = 2 * time.Minute  // Время ожидания записи ответа служебного сервера (профили pprof собираются до 30 секунд и дольше).
 )
 
 // serveHTTP запускает HTTP-сервер с настройками, определенными в конфигурации приложения.
 // Использует http.Server для управления сервером и Gorilla Mux для обработки маршрутов.
-// Производит Graceful Shutdown сервера при получении сигнала завершения.
+// Обслуживает запросы на всех настроенных адресах или сокетах, переданных systemd.
+// Производит Graceful Shutdown сервера при получении сигнала завершения (SIGINT, SIGTERM)
+// и перезапуск без простоя при получении сигнала SIGUSR2.
 func (app *application) serveHTTP() error {
 	// Создание нового HTTP-сервера с использованием настроек из конфигурации приложения.
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", app.config.httpPort),
-		Handler:      app.routes(),
-		ErrorLog:     slog.NewLogLogger(app.logger.Handler(), slog.LevelWarn),
-		IdleTimeout:  defaultIdleTimeout,
-		ReadTimeout:  defaultReadTimeout,
-		WriteTimeout: defaultWriteTimeout,
+		Handler:           app.routes(),
+		ErrorLog:          slog.NewLogLogger(app.logger.Handler(), slog.LevelWarn),
+		IdleTimeout:       app.config().Server.IdleTimeout,
+		ReadTimeout:       app.config().Server.ReadTimeout,
+		ReadHeaderTimeout: app.config().Server.ReadHeaderTimeout,
+		WriteTimeout:      app.config().Server.WriteTimeout,
+		MaxHeaderBytes:    app.config().Server.MaxHeaderBytes,
+	}
+
+	// Поддержка HTTP/2 без TLS (h2c) для внутреннего трафика, например между сервисами за балансировщиком.
+	// ConfigureServer связывает HTTP/2-сервер с srv, чтобы при Graceful Shutdown h2c-соединения получали
+	// GOAWAY и завершали уже начатые запросы.
+	if app.config().Server.H2C {
+		h2s := &http2.Server{
+			MaxConcurrentStreams: uint32(app.config().Server.MaxConcurrentStreams),
+			IdleTimeout:          app.config().Server.IdleTimeout,
+		}
+		err := http2.ConfigureServer(srv, h2s)
+		if err != nil {
+			return err
+		}
+		srv.Handler = h2c.NewHandler(srv.Handler, h2s)
+	}
+
+	// Логгирование действующих настроек сервера.
+	app.logger.Info("server settings",
+		"idle_timeout", srv.IdleTimeout.String(),
+		"read_timeout", srv.ReadTimeout.String(),
+		"read_header_timeout", srv.ReadHeaderTimeout.String(),
+		"write_timeout", srv.WriteTimeout.String(),
+		"shutdown_period", app.config().Server.ShutdownPeriod.String(),
+		"max_header_bytes", srv.MaxHeaderBytes,
+		"h2c", app.config().Server.H2C,
+		"max_concurrent_streams", app.config().Server.MaxConcurrentStreams,
+	)
+
+	// Получение слушающих сокетов: унаследованных от предыдущего процесса при перезапуске,
+	// переданных systemd или открытых по адресам из конфигурации.
+	upg, err := upgrade.New()
+	if err != nil {
+		return err
+	}
+
+	listeners, err := app.listen(upg)
+	if err != nil {
+		return err
+	}
+
+	// Создание служебного сервера, если задан его адрес. Он использует отдельный сокет, чтобы служебные
+	// эндпоинты не были доступны на публичном адресе.
+	var adminSrv *http.Server
+	var adminListener net.Listener
+	if app.config().Admin.Addr != "" {
+		adminSrv = &http.Server{
+			Handler:           app.adminRoutes(),
+			ErrorLog:          slog.NewLogLogger(app.logger.Handler(), slog.LevelWarn),
+			IdleTimeout:       app.config().Server.IdleTimeout,
+			ReadTimeout:       app.config().Server.ReadTimeout,
+			ReadHeaderTimeout: app.config().Server.ReadHeaderTimeout,
+			WriteTimeout:      defaultAdminWriteTimeout,
+			MaxHeaderBytes:    app.config().Server.MaxHeaderBytes,
+		}
+
+		adminListener, err = upg.Listen(parseListenAddress(app.config().Admin.Addr))
+		if err != nil {
+			return err
+		}
 	}
 
 	// Канал для передачи ошибки завершения сервера.
 	shutdownErrorChan := make(chan error)
 
-	// Горутина для обработки сигналов завершения (SIGINT, SIGTERM).
+	// Канал, закрываемый в начале завершения работы для остановки уведомлений watchdog.
+	stoppingChan := make(chan struct{})
+
+	// Отслеживание изменений файла конфигурации и файлов секретов до начала завершения работы.
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go secrets.Watch(watchCtx, defaultConfigWatchInterval, app.watchPaths, app.reloadConfig)
+
+	// Горутина для обработки сигналов завершения (SIGINT, SIGTERM), перезапуска (SIGUSR2)
+	// и перезагрузки конфигурации (SIGHUP).
 	go func() {
-		quitChan := make(chan os.Signal, 1)
-		signal.Notify(quitChan, syscall.SIGINT, syscall.SIGTERM)
-		<-quitChan
+		signalChan := make(chan os.Signal, 1)
+		signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR2, syscall.SIGHUP)
+
+		app.handleSignals(signalChan, upg)
+		signal.Stop(signalChan)
+		close(stoppingChan)
+		stopWatch()
 
 		// Создание контекста с таймаутом для Graceful Shutdown.
-		ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownPeriod)
+		ctx, cancel := context.WithTimeout(context.Background(), app.config().Server.ShutdownPeriod)
 		defer cancel()
 
-		// Вызов Shutdown для Graceful Shutdown сервера и передача результата в канал.
-		shutdownErrorChan <- srv.Shutdown(ctx)
+		// Вызов Shutdown для Graceful Shutdown сервера. Служебный сервер останавливается последним,
+		// чтобы за завершением работы основного сервера можно было наблюдать.
+		err := srv.Shutdown(ctx)
+		if adminSrv != nil {
+			err = errors.Join(err, adminSrv.Shutdown(ctx))
+		}
+
+		// Передача результата в канал.
+		shutdownErrorChan <- err
 	}()
 
-	// Логгирование информации о запуске сервера.
-	app.logger.Info("starting server", slog.Group("server", "addr", srv.Addr))
+	// Запуск обслуживания запросов на каждом слушающем сокете.
+	serveErrorChan := make(chan error, len(listeners)+1)
+	for _, ln := range listeners {
+		app.logger.Info("starting server", slog.Group("server", "addr", ln.Addr().String()), "inherited", upg.Inherited())
 
-	// Запуск сервера для обработки входящих HTTP-запросов.
-	err := srv.ListenAndServe()
-	if !errors.Is(err, http.ErrServerClosed) {
-		return err
+		go func(ln net.Listener) {
+			serveErrorChan <- srv.Serve(ln)
+		}(ln)
+	}
+
+	// Запуск служебного сервера.
+	servers := len(listeners)
+	if adminSrv != nil {
+		servers++
+		app.logger.Info("starting admin server", slog.Group("server", "addr", adminListener.Addr().String()))
+
+		go func() {
+			serveErrorChan <- adminSrv.Serve(adminListener)
+		}()
+	}
+
+	// Сообщение родительскому процессу и systemd о готовности, а также запуск уведомлений watchdog.
+	go func() {
+		err := upg.Ready()
+		if err != nil {
+			app.logger.Warn("failed to notify parent process", "error", err)
+		}
+
+		app.notify(systemd.Ready)
+		app.watchdog(stoppingChan)
+	}()
+
+	// Ожидание остановки обслуживания на всех сокетах.
+	for i := 0; i < servers; i++ {
+		err = <-serveErrorChan
+		if !errors.Is(err, http.ErrServerClosed) {
+			// Остановка остальных серверов, включая служебный, и фоновых задач, чтобы они не продолжали работу
+			// после ошибки одного из сокетов.
+			ctx, cancel := context.WithTimeout(context.Background(), app.config().Server.ShutdownPeriod)
+			defer cancel()
+
+			srv.Shutdown(ctx)
+			if adminSrv != nil {
+				adminSrv.Shutdown(ctx)
+			}
+			app.stopJobs()
+
+			return err
+		}
 	}
 
 	// Ожидание завершения Graceful Shutdown и проверка ошибки завершения.
@@ -67,10 +203,294 @@ func (app *application) serveHTTP() error {
 	}
 
 	// Логгирование информации о завершении работы сервера.
-	app.logger.Info("stopped server", slog.Group("server", "addr", srv.Addr))
+	app.logger.Info("stopped server")
 
-	// Ожидание завершения всех фоновых задач перед завершением.
-	app.wg.Wait()
+	app.stopJobs()
 
 	return nil
 }
+
+// stopJobs ожидает завершения фоновых задач, уже поставленных в очередь, не дольше периода завершения работы.
+// По его истечении контексты выполняющихся задач отменяются, а оставшиеся в очереди задачи отбрасываются.
+func (app *application) stopJobs() {
+	ctx, cancel := context.WithTimeout(context.Background(), app.config().Server.ShutdownPeriod)
+	defer cancel()
+
+	err := app.jobs.Shutdown(ctx)
+	if err != nil {
+		stats := app.jobs.Stats()
+		app.logger.Warn("background jobs did not finish before shutdown", "error", err, "running", stats.Running, "queued", stats.Queued, "retrying", stats.Retrying)
+	}
+	app.logger.Info("stopped background jobs")
+}
+
+// upgrader запускает новый процесс приложения и передает ему слушающие сокеты (реализуется *upgrade.Upgrader).
+type upgrader interface {
+	Upgrade(ctx context.Context) (*os.Process, error)
+}
+
+// handleSignals обрабатывает сигналы из signalChan до получения сигнала завершения или успешного перезапуска
+// и начинает завершение работы: переводит проверку готовности в состояние failing и сообщает о нем systemd.
+// Конфигурация перезагружается в отдельной горутине, чтобы медленная загрузка источников не задерживала
+// обработку сигнала завершения.
+func (app *application) handleSignals(signalChan <-chan os.Signal, upg upgrader) {
+	var (
+		mu        sync.Mutex // Упорядочивает уведомления о перезагрузке и о завершении работы.
+		stopping  bool
+		handedOff bool
+	)
+
+	for sig := range signalChan {
+		if sig == syscall.SIGHUP {
+			// Перезагрузка конфигурации. Для systemd-юнитов с Type=notify сообщается о ее начале и завершении,
+			// если к этому моменту не началось завершение работы.
+			go func() {
+				mu.Lock()
+				if stopping {
+					mu.Unlock()
+					return
+				}
+				app.notify(systemd.Reloading)
+				mu.Unlock()
+
+				app.reloadConfig()
+
+				mu.Lock()
+				defer mu.Unlock()
+				if !stopping {
+					app.notify(systemd.Ready)
+				}
+			}()
+			continue
+		}
+		if sig == syscall.SIGUSR2 {
+			// Запуск нового процесса. При неудаче текущий процесс продолжает обслуживать запросы.
+			handedOff = app.upgrade(upg)
+			if !handedOff {
+				continue
+			}
+		}
+		break
+	}
+
+	// Перевод проверки готовности в состояние failing, чтобы балансировщик перестал направлять запросы.
+	app.health.SetShuttingDown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	stopping = true
+
+	// После передачи управления systemd считает основным новый процесс (MAINPID), и уведомление STOPPING
+	// от текущего процесса было бы воспринято как остановка всего сервиса.
+	if !handedOff {
+		app.notify(systemd.Stopping)
+	}
+}
+
+// listen возвращает слушающие сокеты сервера. Сокеты, переданные systemd, имеют приоритет над адресами
+// из конфигурации и, как и они, передаются новому процессу при перезапуске.
+func (app *application) listen(upg *upgrade.Upgrader) ([]net.Listener, error) {
+	var listeners []net.Listener
+
+	// Сокеты systemd, унаследованные от предыдущего процесса при перезапуске.
+	for _, ln := range upg.TakeInherited("systemd:") {
+		listeners = append(listeners, ln)
+	}
+	if len(listeners) > 0 {
+		return listeners, nil
+	}
+
+	// Сокеты, переданные при активации через systemd.
+	activated, err := systemd.Listeners()
+	if err != nil {
+		return nil, err
+	}
+	for i, ln := range activated {
+		upg.Adopt(fmt.Sprintf("systemd:%d:%s", i, ln.Name), ln.Listener)
+		listeners = append(listeners, ln.Listener)
+	}
+	if len(listeners) > 0 {
+		return listeners, nil
+	}
+
+	// Сокеты по адресам из конфигурации.
+	for _, address := range app.config().HTTPListen {
+		network, address := parseListenAddress(address)
+
+		var ln net.Listener
+		var err error
+		if network == "unix" {
+			ln, err = app.listenUnix(upg, address)
+		} else {
+			ln, err = upg.Listen(network, address)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		listeners = append(listeners, ln)
+	}
+
+	return listeners, nil
+}
+
+// parseListenAddress разбирает адрес из конфигурации: "unix:/path/to/socket" для сокета Unix,
+// иначе адрес TCP вида "host:port" или ":port".
+func parseListenAddress(address string) (network, addr string) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", address
+}
+
+// listenUnix открывает сокет Unix по пути path и настраивает права доступа и владельца файла сокета.
+// Если права доступа заданы в конфигурации, сокет создается с маской 0177, поэтому до применения
+// настроек к нему может подключиться только пользователь процесса.
+func (app *application) listenUnix(upg *upgrade.Upgrader, path string) (net.Listener, error) {
+	mode, err := parseSocketMode(app.config().UnixSocket.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Маска действует на весь процесс, но сокеты открываются при запуске, до обработки запросов.
+	if mode != 0 {
+		mask := syscall.Umask(0o177)
+		defer syscall.Umask(mask)
+	}
+
+	ln, err := upg.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.configureUnixSocket(path, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return ln, nil
+}
+
+// parseSocketMode разбирает права доступа к сокету Unix, заданные в восьмеричном виде, например "0660".
+// Для пустой строки возвращается 0, что означает "не изменять".
+func parseSocketMode(value string) (os.FileMode, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid unix socket mode %q: %w", value, err)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// configureUnixSocket устанавливает владельца и права доступа mode файла сокета Unix согласно конфигурации.
+// Права доступа устанавливаются последними, чтобы они расширялись только после смены владельца.
+func (app *application) configureUnixSocket(path string, mode os.FileMode) error {
+	// Установка владельца в формате "user", "user:group" или ":group".
+	if app.config().UnixSocket.Owner != "" {
+		uid, gid, err := lookupOwner(app.config().UnixSocket.Owner)
+		if err != nil {
+			return err
+		}
+
+		err = os.Chown(path, uid, gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	if mode != 0 {
+		err := os.Chmod(path, mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lookupOwner преобразует владельца в формате "user:group" в числовые идентификаторы.
+// Для незаданной части возвращается -1, что означает "не изменять".
+func lookupOwner(owner string) (uid, gid int, err error) {
+	userName, groupName, _ := strings.Cut(owner, ":")
+	uid, gid = -1, -1
+
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			return 0, 0, err
+		}
+		uid, _ = strconv.Atoi(u.Uid)
+	}
+
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			return 0, 0, err
+		}
+		gid, _ = strconv.Atoi(g.Gid)
+	}
+
+	return uid, gid, nil
+}
+
+// notify отправляет уведомление о состоянии systemd, если приложение запущено под его управлением.
+func (app *application) notify(state string) {
+	_, err := systemd.Notify(state)
+	if err != nil {
+		app.logger.Warn("failed to notify systemd", "state", state, "error", err)
+	}
+}
+
+// watchdog периодически отправляет systemd уведомления watchdog, пока не будет закрыт канал stop.
+// Если watchdog не включен, сразу возвращает управление.
+func (app *application) watchdog(stop <-chan struct{}) {
+	interval, err := systemd.WatchdogInterval()
+	if err != nil {
+		app.logger.Warn("invalid systemd watchdog settings", "error", err)
+		return
+	}
+	if interval == 0 {
+		return
+	}
+
+	// Уведомления отправляются с половиной интервала, чтобы задержки не привели к перезапуску сервиса.
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.notify(systemd.Watchdog)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// upgrade запускает новый процесс приложения, передавая ему слушающие сокеты, и ожидает его готовности.
+// Возвращает true, если новый процесс готов и текущий должен завершить работу.
+func (app *application) upgrade(upg upgrader) bool {
+	app.logger.Info("starting upgrade")
+
+	// Watchdog systemd должен следить за новым процессом, а не за текущим.
+	os.Unsetenv("WATCHDOG_PID")
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultUpgradeTimeout)
+	defer cancel()
+
+	process, err := upg.Upgrade(ctx)
+	if err != nil {
+		app.logger.Error("upgrade failed", "error", err)
+		return false
+	}
+
+	app.logger.Info("upgrade complete, draining old process", "pid", process.Pid)
+
+	// Передача systemd PID нового основного процесса (требует NotifyAccess=all в unit-файле).
+	app.notify(fmt.Sprintf("MAINPID=%d", process.Pid))
+	return true
+}
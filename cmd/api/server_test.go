@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"apiapp/internal/health"
+	"apiapp/internal/jobs"
+	"apiapp/internal/settings"
+	"apiapp/internal/upgrade"
+
+	"golang.org/x/net/http2"
+)
+
+// fakeUpgrader имитирует перезапуск: возвращает процесс с PID pid или ошибку err.
+type fakeUpgrader struct {
+	pid int
+	err error
+}
+
+func (u fakeUpgrader) Upgrade(ctx context.Context) (*os.Process, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	return &os.Process{Pid: u.pid}, nil
+}
+
+// Тестирование уведомлений systemd при завершении работы и перезапуске.
+func TestHandleSignals(t *testing.T) {
+	// Сокет, имитирующий сокет уведомлений systemd.
+	notifySocket := t.TempDir() + "/notify.sock"
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: notifySocket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", notifySocket)
+
+	tests := []struct {
+		name     string
+		signals  []os.Signal
+		upgrader fakeUpgrader
+		want     []string
+	}{
+		{
+			name:    "завершение работы",
+			signals: []os.Signal{syscall.SIGTERM},
+			want:    []string{"STOPPING=1"},
+		},
+		{
+			name:     "неудачный перезапуск и завершение работы",
+			signals:  []os.Signal{syscall.SIGUSR2, syscall.SIGINT},
+			upgrader: fakeUpgrader{err: errors.New("new process exited")},
+			want:     []string{"STOPPING=1"},
+		},
+		{
+			// После передачи управления новому процессу STOPPING не отправляется.
+			name:     "успешный перезапуск",
+			signals:  []os.Signal{syscall.SIGUSR2},
+			upgrader: fakeUpgrader{pid: 4242},
+			want:     []string{"MAINPID=4242"},
+		},
+	}
+
+	for _, tt := range tests {
+		app := &application{health: health.New(0), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+		signalChan := make(chan os.Signal, len(tt.signals))
+		for _, sig := range tt.signals {
+			signalChan <- sig
+		}
+		close(signalChan)
+
+		app.handleSignals(signalChan, tt.upgrader)
+
+		if !app.health.ShuttingDown() {
+			t.Errorf("%s: expected readiness to be failing", tt.name)
+		}
+
+		// Чтение всех отправленных уведомлений.
+		var got []string
+		buf := make([]byte, 256)
+		for {
+			conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+			n, err := conn.Read(buf)
+			if err != nil {
+				break
+			}
+			got = append(got, string(buf[:n]))
+		}
+
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: expected notifications %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+// Тестирование перезагрузки конфигурации, не задерживающей завершение работы.
+func TestHandleSignalsReload(t *testing.T) {
+	notifySocket := t.TempDir() + "/notify.sock"
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: notifySocket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", notifySocket)
+
+	// Загрузка конфигурации блокируется до закрытия канала release.
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	app := &application{health: health.New(0), logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	app.settings = settings.NewReloader(&config{}, func() (*config, error) {
+		close(loading)
+		<-release
+		return &config{}, nil
+	})
+
+	signalChan := make(chan os.Signal, 2)
+	signalChan <- syscall.SIGHUP
+	done := make(chan struct{})
+	go func() {
+		app.handleSignals(signalChan, fakeUpgrader{})
+		close(done)
+	}()
+
+	<-loading
+	signalChan <- syscall.SIGTERM
+	close(signalChan)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected shutdown not to wait for the configuration reload")
+	}
+	close(release)
+
+	// После STOPPING уведомление о завершении перезагрузки не отправляется.
+	var got []string
+	buf := make([]byte, 256)
+	for {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			break
+		}
+		got = append(got, string(buf[:n]))
+	}
+
+	want := []string{"RELOADING=1", "STOPPING=1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected notifications %v, got %v", want, got)
+	}
+}
+
+// Тестирование завершения h2c-запросов при Graceful Shutdown.
+func TestServeHTTPH2CShutdown(t *testing.T) {
+	socket := t.TempDir() + "/api.sock"
+	t.Setenv("HTTP_LISTEN", "unix:"+socket)
+	t.Setenv("HTTP_H2C", "true")
+
+	var cfg config
+	err := loadConfig(&cfg, []settings.Source{settings.Env{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	app := &application{
+		settings: settings.NewReloader(&cfg, nil),
+		health:   health.New(0),
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		jobs:     jobs.New(jobs.Options{QueueSize: 1}),
+	}
+
+	// Подписка на SIGTERM не дает сигналу, отправленному тестом самому себе, завершить процесс.
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- app.serveHTTP()
+	}()
+
+	// Клиент HTTP/2 без TLS, подключающийся к сокету Unix.
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
+	}}
+
+	// Ожидание запуска сервера.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := client.Get("http://api/livez")
+		if err == nil {
+			resp.Body.Close()
+			if resp.ProtoMajor != 2 {
+				t.Fatalf("expected HTTP/2, got %s", resp.Proto)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Проверка готовности, выполняющаяся до отмены блокировки.
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	app.health.Register(health.Check{Name: "slow", Func: func(ctx context.Context) error {
+		close(entered)
+		<-release
+		return nil
+	}})
+
+	respErr := make(chan error, 1)
+	go func() {
+		resp, err := client.Get("http://api/readyz")
+		if err == nil {
+			_, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		respErr <- err
+	}()
+
+	// Начало завершения работы во время выполнения запроса.
+	<-entered
+	syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	for !app.health.ShuttingDown() {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	err = <-respErr
+	if err != nil {
+		t.Fatalf("expected the in-flight request to complete, got %v", err)
+	}
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected serveHTTP to return after shutdown")
+	}
+
+	// Соединение закрыто после GOAWAY, поэтому новые запросы не обрабатываются.
+	resp, err := client.Get("http://api/livez")
+	if err == nil {
+		resp.Body.Close()
+		t.Errorf("expected the h2c connection to be closed after shutdown")
+	}
+}
+
+// Тестирование прав доступа к файлу сокета Unix.
+func TestListenUnix(t *testing.T) {
+	tests := []struct {
+		mode    string
+		owner   string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{mode: "0660", want: 0o660},
+		{mode: "0666", want: 0o666},
+		// Если владельца не удалось установить, права доступа остаются ограниченными.
+		{mode: "0666", owner: "no-such-user-apiapp", want: 0o600, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var cfg config
+		cfg.UnixSocket.Mode = tt.mode
+		cfg.UnixSocket.Owner = tt.owner
+		app := &application{settings: settings.NewReloader(&cfg, nil)}
+
+		upg, err := upgrade.New()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		socket := t.TempDir() + "/api.sock"
+		ln, err := app.listenUnix(upg, socket)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("mode %s, owner %q: unexpected error: %v", tt.mode, tt.owner, err)
+		}
+
+		info, err := os.Stat(socket)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if info.Mode().Perm() != tt.want {
+			t.Errorf("mode %s, owner %q: expected permissions %v, got %v", tt.mode, tt.owner, tt.want, info.Mode().Perm())
+		}
+
+		if ln != nil {
+			ln.Close()
+		}
+	}
+}
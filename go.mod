@@ -3,8 +3,20 @@ module apiapp
 go 1.21.0
 
 require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/fxamacker/cbor/v2 v2.6.0
 	github.com/gorilla/mux v1.8.1
 	github.com/lmittmann/tint v1.0.4
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	github.com/vmihailenco/msgpack/v5 v5.4.1
 	golang.org/x/crypto v0.20.0
 	golang.org/x/exp v0.0.0-20240222234643-814bf88cf225
+	golang.org/x/net v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/text v0.14.0 // indirect
 )
@@ -0,0 +1,418 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Variable описывает переменную окружения, установленную из dotenv-файла.
+type Variable struct {
+	Key  string // Имя переменной.
+	File string // Файл, из которого взято значение.
+	Line int    // Номер строки в файле.
+}
+
+// Files возвращает список dotenv-файлов для окружения appEnv (например, development, test или production)
+// в порядке убывания приоритета: .env.<appEnv>.local, .env.local, .env.<appEnv>, .env.
+// Файл .env.local не используется в окружении test, чтобы тесты не зависели от локальных настроек.
+// Если appEnv пусто, возвращаются только .env.local и .env.
+func Files(appEnv string) []string {
+	if appEnv == "" {
+		return []string{".env.local", ".env"}
+	}
+
+	files := []string{".env." + appEnv + ".local"}
+	if appEnv != "test" {
+		files = append(files, ".env.local")
+	}
+	return append(files, ".env."+appEnv, ".env")
+}
+
+// Load загружает переменные окружения из dotenv-файлов filenames. Файлы перечисляются в порядке убывания
+// приоритета: значение из более раннего файла заменяет значение из более позднего. Переменные, уже заданные
+// в окружении процесса, никогда не переопределяются. Отсутствующие файлы пропускаются.
+// Возвращает установленные переменные с указанием файла, из которого взято итоговое значение каждой из них.
+//
+// Формат файла:
+//
+//	# комментарий
+//	export KEY=value          # префикс export необязателен, комментарий после значения игнорируется
+//	KEY='literal $value'      # в одинарных кавычках значение берется как есть
+//	KEY="line\nnext ${OTHER}" # в двойных кавычках обрабатываются \n, \r, \t, \", \\, \$ и подстановки
+//	KEY=${OTHER:-default}     # подстановки: $OTHER, ${OTHER}, ${OTHER:-default}, ${OTHER-default}
+//
+// Значения в двойных и одинарных кавычках могут занимать несколько строк. В подстановках значение переменной
+// ищется сначала в окружении процесса до загрузки файлов, затем среди значений, присвоенных выше в том же файле,
+// и затем в файлах с меньшим приоритетом.
+func Load(filenames ...string) ([]Variable, error) {
+	var (
+		loaded []Variable
+		index  = make(map[string]int)    // Позиция переменной в loaded.
+		values = make(map[string]string) // Значения из уже загруженных файлов.
+	)
+
+	// Окружение процесса до загрузки файлов. Переменные из него не переопределяются.
+	environ := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		environ[key] = value
+	}
+	lookupEnviron := func(key string) (string, bool) {
+		value, ok := environ[key]
+		return value, ok
+	}
+	lookupLoaded := func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+
+	// Файлы загружаются начиная с наименее приоритетного, чтобы в подстановках можно было ссылаться
+	// на значения из общих файлов, например из .env.
+	for i := len(filenames) - 1; i >= 0; i-- {
+		filename := filenames[i]
+
+		data, err := os.ReadFile(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		vars, err := parse(filename, string(data), lookupEnviron, lookupLoaded)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range vars {
+			// Переменные, заданные в окружении процесса до загрузки файлов, не переопределяются.
+			if _, exists := environ[v.key]; exists {
+				continue
+			}
+
+			err := os.Setenv(v.key, v.value)
+			if err != nil {
+				return nil, err
+			}
+			values[v.key] = v.value
+
+			variable := Variable{Key: v.key, File: filename, Line: v.line}
+			if pos, fromFile := index[v.key]; fromFile {
+				loaded[pos] = variable
+				continue
+			}
+			index[v.key] = len(loaded)
+			loaded = append(loaded, variable)
+		}
+	}
+
+	return loaded, nil
+}
+
+// assignment - присваивание значения переменной в dotenv-файле.
+type assignment struct {
+	key   string
+	value string
+	line  int
+}
+
+// parser разбирает содержимое dotenv-файла.
+type parser struct {
+	data     string
+	pos      int
+	line     int
+	lookup   func(key string) (string, bool)
+	values   map[string]string // Значения, присвоенные выше в этом же файле.
+	fallback func(key string) (string, bool)
+}
+
+// parse разбирает содержимое dotenv-файла. Для подстановок сначала используется lookup, затем
+// значения, присвоенные выше в этом же файле, и затем fallback, если он задан.
+func parse(filename, data string, lookup, fallback func(key string) (string, bool)) ([]assignment, error) {
+	p := &parser{
+		data:     strings.ReplaceAll(data, "\r\n", "\n"),
+		line:     1,
+		lookup:   lookup,
+		values:   make(map[string]string),
+		fallback: fallback,
+	}
+
+	var assignments []assignment
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.data) {
+			return assignments, nil
+		}
+
+		// Пропуск строк-комментариев.
+		if p.data[p.pos] == '#' {
+			p.skipLine()
+			continue
+		}
+
+		line := p.line
+		a, err := p.assignment()
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filename, line, err)
+		}
+		a.line = line
+
+		p.values[a.key] = a.value
+		assignments = append(assignments, a)
+	}
+}
+
+// assignment разбирает одну строку вида [export] KEY=value.
+func (p *parser) assignment() (assignment, error) {
+	key := p.name()
+	if key == "export" && p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.skipSpaces()
+		key = p.name()
+	}
+	if key == "" {
+		return assignment{}, errors.New("expected variable name")
+	}
+
+	p.skipSpaces()
+	if p.pos >= len(p.data) || p.data[p.pos] != '=' {
+		return assignment{}, fmt.Errorf("expected = after %s", key)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var (
+		value string
+		err   error
+	)
+	switch {
+	case p.pos < len(p.data) && p.data[p.pos] == '\'':
+		value, err = p.singleQuoted()
+	case p.pos < len(p.data) && p.data[p.pos] == '"':
+		value, err = p.doubleQuoted()
+	default:
+		value, err = p.unquoted()
+	}
+	if err != nil {
+		return assignment{}, fmt.Errorf("%s: %w", key, err)
+	}
+
+	// После значения в кавычках допускается только комментарий.
+	p.skipSpaces()
+	if p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '#' {
+		return assignment{}, fmt.Errorf("%s: unexpected characters after value", key)
+	}
+	p.skipLine()
+
+	return assignment{key: key, value: value}, nil
+}
+
+// name читает имя переменной: буквы, цифры, подчеркивания и точки, не начинающиеся с цифры.
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.data) && isNameChar(p.data[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+// singleQuoted читает значение в одинарных кавычках без обработки экранирования и подстановок.
+func (p *parser) singleQuoted() (string, error) {
+	p.pos++
+	end := strings.IndexByte(p.data[p.pos:], '\'')
+	if end < 0 {
+		return "", errors.New("unterminated single-quoted value")
+	}
+
+	value := p.data[p.pos : p.pos+end]
+	p.line += strings.Count(value, "\n")
+	p.pos += end + 1
+	return value, nil
+}
+
+// doubleQuoted читает значение в двойных кавычках, обрабатывая экранирование и подстановки.
+func (p *parser) doubleQuoted() (string, error) {
+	p.pos++
+	var sb strings.Builder
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+
+		case c == '\\' && p.pos+1 < len(p.data):
+			p.pos += 2
+			switch next := p.data[p.pos-1]; next {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '"', '\\', '$':
+				sb.WriteByte(next)
+			default:
+				// Неизвестные последовательности сохраняются как есть.
+				sb.WriteByte('\\')
+				sb.WriteByte(next)
+			}
+
+		case c == '$':
+			value, err := p.expand()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", errors.New("unterminated double-quoted value")
+}
+
+// unquoted читает значение без кавычек до конца строки или до комментария, отделенного пробелом,
+// и обрабатывает подстановки. Пробелы по краям значения удаляются.
+func (p *parser) unquoted() (string, error) {
+	var sb strings.Builder
+
+	for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+		c := p.data[p.pos]
+		if c == '#' && (p.pos == 0 || p.data[p.pos-1] == ' ' || p.data[p.pos-1] == '\t') {
+			break
+		}
+
+		if c == '$' {
+			value, err := p.expand()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+			continue
+		}
+
+		sb.WriteByte(c)
+		p.pos++
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// expand разбирает подстановку, начинающуюся с символа $, и возвращает ее значение. Символ $,
+// за которым не следует имя переменной, сохраняется как есть.
+func (p *parser) expand() (string, error) {
+	p.pos++
+
+	// Краткая форма $NAME.
+	if p.pos >= len(p.data) || p.data[p.pos] != '{' {
+		name := p.name()
+		if name == "" {
+			return "$", nil
+		}
+		value, _ := p.resolve(name)
+		return value, nil
+	}
+
+	// Полная форма ${NAME}, ${NAME:-default} или ${NAME-default}.
+	p.pos++
+	name := p.name()
+	if name == "" {
+		return "", errors.New("invalid variable name in ${...}")
+	}
+
+	switch {
+	case strings.HasPrefix(p.data[p.pos:], "}"):
+		p.pos++
+		value, _ := p.resolve(name)
+		return value, nil
+
+	case strings.HasPrefix(p.data[p.pos:], ":-"), strings.HasPrefix(p.data[p.pos:], "-"):
+		// С двоеточием значение по умолчанию используется и для пустой переменной.
+		emptyIsUnset := p.data[p.pos] == ':'
+		if emptyIsUnset {
+			p.pos++
+		}
+		p.pos++
+
+		end := strings.IndexByte(p.data[p.pos:], '}')
+		if end < 0 {
+			return "", errors.New("unterminated ${...}")
+		}
+		fallback := p.data[p.pos : p.pos+end]
+		p.line += strings.Count(fallback, "\n")
+		p.pos += end + 1
+
+		value, ok := p.resolve(name)
+		if !ok || (emptyIsUnset && value == "") {
+			return fallback, nil
+		}
+		return value, nil
+	}
+
+	return "", errors.New("unterminated ${...}")
+}
+
+// resolve возвращает значение переменной для подстановки.
+func (p *parser) resolve(name string) (string, bool) {
+	if value, ok := p.lookup(name); ok {
+		return value, true
+	}
+	if value, ok := p.values[name]; ok {
+		return value, true
+	}
+	if p.fallback != nil {
+		return p.fallback(name)
+	}
+	return "", false
+}
+
+// skipBlank пропускает пробелы и пустые строки.
+func (p *parser) skipBlank() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case '\n':
+			p.line++
+		case ' ', '\t', '\r':
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// skipSpaces пропускает пробелы и табуляции в пределах строки.
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipLine переходит к началу следующей строки.
+func (p *parser) skipLine() {
+	end := strings.IndexByte(p.data[p.pos:], '\n')
+	if end < 0 {
+		p.pos = len(p.data)
+		return
+	}
+	p.pos += end + 1
+	p.line++
+}
+
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		return true
+	case c >= '0' && c <= '9' || c == '.':
+		return !first
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Тестирование разбора dotenv-файла.
+func TestParse(t *testing.T) {
+	data := strings.Join([]string{
+		"# комментарий",
+		"",
+		"PLAIN=value",
+		"export EXPORTED = spaced value  # комментарий",
+		"HASH=a#b",
+		"SINGLE='literal $PLAIN \\n'",
+		`DOUBLE="line\nnext \"quoted\" \$PLAIN"`,
+		"EXPANDED=${PLAIN}-$PLAIN-${REAL}",
+		"EMPTY=",
+		"DEFAULTS=${MISSING:-fallback}/${EMPTY:-empty}/${EMPTY-set}",
+		`MULTI="first`,
+		`second"`,
+		"DOLLAR=$ 5",
+		"LAST=end",
+	}, "\r\n")
+
+	lookup := func(key string) (string, bool) {
+		if key == "REAL" {
+			return "from-env", true
+		}
+		return "", false
+	}
+
+	assignments, err := parse(".env", data, lookup, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "spaced value",
+		"HASH":     "a#b",
+		"SINGLE":   `literal $PLAIN \n`,
+		"DOUBLE":   "line\nnext \"quoted\" $PLAIN",
+		"EXPANDED": "value-value-from-env",
+		"DEFAULTS": "fallback/empty/",
+		"EMPTY":    "",
+		"MULTI":    "first\nsecond",
+		"DOLLAR":   "$ 5",
+		"LAST":     "end",
+	}
+
+	if len(assignments) != len(want) {
+		t.Fatalf("expected %d assignments, got %d: %v", len(want), len(assignments), assignments)
+	}
+	for _, a := range assignments {
+		if a.value != want[a.key] {
+			t.Errorf("%s: expected %q, got %q", a.key, want[a.key], a.value)
+		}
+	}
+
+	// Номер строки учитывает многострочные значения.
+	if last := assignments[len(assignments)-1]; last.line != 14 {
+		t.Errorf("expected LAST on line 14, got %d", last.line)
+	}
+}
+
+// Тестирование сообщений об ошибках разбора.
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"KEY", ".env:1: expected = after KEY"},
+		{"\n=value", ".env:2: expected variable name"},
+		{`KEY="open`, ".env:1: KEY: unterminated double-quoted value"},
+		{"KEY='open", ".env:1: KEY: unterminated single-quoted value"},
+		{"KEY='a' b", ".env:1: KEY: unexpected characters after value"},
+		{"KEY=${OTHER", ".env:1: KEY: unterminated ${...}"},
+	}
+
+	for _, tt := range tests {
+		_, err := parse(".env", tt.data, os.LookupEnv, nil)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%q: expected error %q, got %v", tt.data, tt.want, err)
+		}
+	}
+}
+
+// Тестирование загрузки файлов без переопределения переменных окружения.
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	base := filepath.Join(dir, ".env")
+
+	writeFile(t, local, "# локальные значения\nDOTENV_TEST_A=local-$DOTENV_TEST_B\n")
+	writeFile(t, base, "DOTENV_TEST_A=base\nDOTENV_TEST_B=base-$DOTENV_TEST_A\nDOTENV_TEST_C=base\n")
+
+	// t.Setenv восстанавливает исходные значения после теста, в том числе для удаленных переменных.
+	t.Setenv("DOTENV_TEST_C", "real")
+	t.Setenv("DOTENV_TEST_A", "")
+	os.Unsetenv("DOTENV_TEST_A")
+	t.Setenv("DOTENV_TEST_B", "")
+	os.Unsetenv("DOTENV_TEST_B")
+
+	loaded, err := Load(local, filepath.Join(dir, ".env.missing"), base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, want := range map[string]string{"DOTENV_TEST_A": "local-base-base", "DOTENV_TEST_B": "base-base", "DOTENV_TEST_C": "real"} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+
+	// Для каждой переменной сообщается файл, из которого взято итоговое значение.
+	want := []Variable{{Key: "DOTENV_TEST_A", File: local, Line: 2}, {Key: "DOTENV_TEST_B", File: base, Line: 2}}
+	if len(loaded) != len(want) || loaded[0] != want[0] || loaded[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, loaded)
+	}
+}
+
+// Тестирование порядка поиска значений для подстановок: окружение процесса, значения выше в том же файле,
+// файлы с меньшим приоритетом.
+func TestLoadExpansionOrder(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	base := filepath.Join(dir, ".env")
+
+	writeFile(t, local, "DOTENV_TEST_HOST=b\nDOTENV_TEST_URL=http://$DOTENV_TEST_HOST\nDOTENV_TEST_PORT_URL=:$DOTENV_TEST_PORT\nDOTENV_TEST_REAL_URL=http://$DOTENV_TEST_REAL\n")
+	writeFile(t, base, "DOTENV_TEST_HOST=a\nDOTENV_TEST_PORT=80\nDOTENV_TEST_REAL=file\n")
+
+	t.Setenv("DOTENV_TEST_REAL", "real")
+	for _, key := range []string{"DOTENV_TEST_HOST", "DOTENV_TEST_URL", "DOTENV_TEST_PORT", "DOTENV_TEST_PORT_URL", "DOTENV_TEST_REAL_URL"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	_, err := Load(local, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for key, want := range map[string]string{
+		"DOTENV_TEST_URL":      "http://b",
+		"DOTENV_TEST_PORT_URL": ":80",
+		"DOTENV_TEST_REAL_URL": "http://real",
+	} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+}
+
+// Тестирование выбора файлов по окружению.
+func TestFiles(t *testing.T) {
+	tests := map[string]string{
+		"":            ".env.local .env",
+		"development": ".env.development.local .env.local .env.development .env",
+		"test":        ".env.test.local .env.test .env",
+	}
+
+	for appEnv, want := range tests {
+		if got := strings.Join(Files(appEnv), " "); got != want {
+			t.Errorf("%q: expected %q, got %q", appEnv, want, got)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
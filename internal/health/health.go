@@ -0,0 +1,258 @@
+//Пакет health предоставляет реестр проверок состояния приложения для эндпоинтов liveness и readiness
+//с тайм-аутами, признаком критичности и кэшированием результатов.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Статусы проверок и итогового отчета.
+const (
+	StatusOK       = "ok"       // Все проверки успешны.
+	StatusDegraded = "degraded" // Не прошли только некритичные проверки.
+	StatusFailing  = "failing"  // Не прошла хотя бы одна критичная проверка.
+)
+
+// defaultTimeout - тайм-аут проверки, если он не задан при регистрации.
+const defaultTimeout = 5 * time.Second
+
+// Ошибки, которые возвращает проверка готовности, когда экземпляр выведен из ротации.
+var (
+	ErrShuttingDown = errors.New("server is shutting down")
+	ErrMaintenance  = errors.New("server is in maintenance mode")
+)
+
+// CheckFunc выполняет проверку компонента и возвращает ошибку, если компонент неработоспособен.
+type CheckFunc func(ctx context.Context) error
+
+// Check описывает зарегистрированную проверку.
+type Check struct {
+	Name     string        // Уникальное имя проверки, например "database".
+	Func     CheckFunc     // Функция проверки.
+	Timeout  time.Duration // Тайм-аут выполнения проверки (по умолчанию 5 секунд).
+	Critical bool          // Неудача критичной проверки делает статус failing, некритичной - degraded.
+	Liveness bool          // Проверка участвует также в liveness (по умолчанию только в readiness).
+}
+
+// Result содержит результат выполнения одной проверки.
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report содержит итоговый статус и результаты отдельных проверок.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Failing возвращает true, если итоговый статус отчета - failing.
+func (r Report) Failing() bool {
+	return r.Status == StatusFailing
+}
+
+// entry хранит проверку и кэшированный результат ее последнего выполнения.
+type entry struct {
+	check Check
+
+	mu       sync.Mutex // Не дает выполнять одну и ту же проверку параллельно.
+	result   Result
+	failed   bool
+	cachedAt time.Time
+}
+
+// Registry - потокобезопасный реестр проверок состояния.
+type Registry struct {
+	cacheTTL     time.Duration
+	shuttingDown atomic.Bool
+	maintenance  atomic.Bool
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// New создает реестр проверок. Результаты проверок кэшируются на время cacheTTL,
+// чтобы частые запросы к эндпоинтам не перегружали проверяемые компоненты.
+func New(cacheTTL time.Duration) *Registry {
+	return &Registry{
+		cacheTTL: cacheTTL,
+		entries:  make(map[string]*entry),
+	}
+}
+
+// Register регистрирует проверку. Проверка с тем же именем заменяет зарегистрированную ранее.
+func (r *Registry) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = defaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[check.Name] = &entry{check: check}
+}
+
+// SetShuttingDown переводит проверку готовности в состояние failing. Вызывается в начале Graceful Shutdown,
+// чтобы балансировщик нагрузки перестал направлять запросы в экземпляр.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// ShuttingDown возвращает true, если было начато завершение работы.
+func (r *Registry) ShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+// SetMaintenance включает или выключает режим обслуживания. В режиме обслуживания проверка готовности
+// имеет статус failing, а liveness-проверки не затрагиваются.
+func (r *Registry) SetMaintenance(enabled bool) {
+	r.maintenance.Store(enabled)
+}
+
+// Liveness выполняет проверки, отмеченные как Liveness, и возвращает итоговый отчет.
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return r.run(ctx, func(c Check) bool { return c.Liveness })
+}
+
+// Readiness выполняет все проверки и возвращает итоговый отчет.
+// После вызова SetShuttingDown и в режиме обслуживания отчет всегда имеет статус failing.
+func (r *Registry) Readiness(ctx context.Context) Report {
+	report := r.run(ctx, func(Check) bool { return true })
+
+	if r.maintenance.Load() {
+		report = report.withOverride("maintenance", ErrMaintenance)
+	}
+	if r.ShuttingDown() {
+		report = report.withOverride("shutdown", ErrShuttingDown)
+	}
+
+	return report
+}
+
+// withOverride добавляет в начало отчета неуспешный критичный результат с указанными именем и ошибкой
+// и переводит отчет в статус failing.
+func (r Report) withOverride(name string, err error) Report {
+	r.Status = StatusFailing
+	r.Checks = append([]Result{{
+		Name:      name,
+		Status:    StatusFailing,
+		Critical:  true,
+		Error:     err.Error(),
+		Duration:  "0s",
+		CheckedAt: time.Now(),
+	}}, r.Checks...)
+
+	return r
+}
+
+// run параллельно выполняет выбранные проверки и собирает отчет.
+func (r *Registry) run(ctx context.Context, include func(Check) bool) Report {
+	r.mu.RLock()
+	var entries []*entry
+	for _, e := range r.entries {
+		if include(e.check) {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+	failed := make([]bool, len(entries))
+
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i], failed[i] = r.execute(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for i, result := range results {
+		switch {
+		case failed[i] && result.Critical:
+			report.Status = StatusFailing
+		case failed[i] && report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+
+	return report
+}
+
+// execute выполняет проверку с тайм-аутом или возвращает кэшированный результат, если он еще актуален.
+func (r *Registry) execute(ctx context.Context, e *entry) (Result, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.cachedAt.IsZero() && time.Since(e.cachedAt) < r.cacheTTL {
+		return e.result, e.failed
+	}
+
+	// Проверка выполняется в контексте, отвязанном от отмены запроса, только с собственным тайм-аутом:
+	// результат кэшируется и используется другими запросами, поэтому не должен зависеть от отключения клиента.
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runCheck(checkCtx, e.check.Func)
+	duration := time.Since(start)
+
+	result := Result{
+		Name:      e.check.Name,
+		Status:    StatusOK,
+		Critical:  e.check.Critical,
+		Duration:  duration.String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+
+	// Если запрос был отменен во время проверки, результат не кэшируется.
+	if ctx.Err() != nil {
+		return result, err != nil
+	}
+
+	e.result, e.failed, e.cachedAt = result, err != nil, time.Now()
+	return e.result, e.failed
+}
+
+// runCheck выполняет функцию проверки, прерывая ожидание по истечении тайм-аута контекста
+// и преобразуя панику в ошибку.
+func runCheck(ctx context.Context, fn CheckFunc) error {
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- errors.New("check panicked")
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
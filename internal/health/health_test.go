@@ -0,0 +1,166 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// check возвращает функцию проверки, которая возвращает err и увеличивает счетчик вызовов calls.
+func check(calls *atomic.Int32, err error) CheckFunc {
+	return func(ctx context.Context) error {
+		calls.Add(1)
+		return err
+	}
+}
+
+// Тестирование итогового статуса отчета.
+func TestStatusAggregation(t *testing.T) {
+	errDown := errors.New("down")
+	var calls atomic.Int32
+
+	tests := []struct {
+		name   string
+		checks []Check
+		want   string
+	}{
+		{"без проверок", nil, StatusOK},
+		{"все проверки успешны", []Check{{Name: "db", Func: check(&calls, nil), Critical: true}, {Name: "cache", Func: check(&calls, nil)}}, StatusOK},
+		{"некритичная неудача", []Check{{Name: "db", Func: check(&calls, nil), Critical: true}, {Name: "cache", Func: check(&calls, errDown)}}, StatusDegraded},
+		{"критичная неудача", []Check{{Name: "db", Func: check(&calls, errDown), Critical: true}, {Name: "cache", Func: check(&calls, errDown)}}, StatusFailing},
+	}
+
+	for _, tt := range tests {
+		r := New(0)
+		for _, c := range tt.checks {
+			r.Register(c)
+		}
+
+		report := r.Readiness(context.Background())
+		if report.Status != tt.want {
+			t.Errorf("%s: expected status %q, got %q", tt.name, tt.want, report.Status)
+		}
+		if report.Failing() != (tt.want == StatusFailing) {
+			t.Errorf("%s: unexpected Failing() %v", tt.name, report.Failing())
+		}
+		if len(report.Checks) != len(tt.checks) {
+			t.Errorf("%s: expected %d results, got %d", tt.name, len(tt.checks), len(report.Checks))
+		}
+	}
+}
+
+// Тестирование отбора проверок liveness, сортировки результатов и переопределения статуса готовности.
+func TestLivenessAndReadiness(t *testing.T) {
+	var calls atomic.Int32
+	r := New(0)
+	r.Register(Check{Name: "queue", Func: check(&calls, nil)})
+	r.Register(Check{Name: "goroutines", Func: check(&calls, errors.New("too many")), Critical: true, Liveness: true})
+	r.Register(Check{Name: "db", Func: check(&calls, nil), Critical: true})
+
+	liveness := r.Liveness(context.Background())
+	if liveness.Status != StatusFailing || len(liveness.Checks) != 1 || liveness.Checks[0].Error != "too many" {
+		t.Errorf("unexpected liveness report %+v", liveness)
+	}
+
+	readiness := r.Readiness(context.Background())
+	var names []string
+	for _, result := range readiness.Checks {
+		names = append(names, result.Name)
+	}
+	if len(names) != 3 || names[0] != "db" || names[1] != "goroutines" || names[2] != "queue" {
+		t.Errorf("expected results sorted by name, got %v", names)
+	}
+
+	// Режим обслуживания и завершение работы добавляют критичные результаты в начало отчета.
+	r = New(0)
+	r.Register(Check{Name: "db", Func: check(&calls, nil), Critical: true})
+	r.SetMaintenance(true)
+	r.SetShuttingDown()
+
+	readiness = r.Readiness(context.Background())
+	if readiness.Status != StatusFailing || len(readiness.Checks) != 3 {
+		t.Fatalf("unexpected readiness report %+v", readiness)
+	}
+	if readiness.Checks[0].Error != ErrShuttingDown.Error() || readiness.Checks[1].Error != ErrMaintenance.Error() {
+		t.Errorf("unexpected override results %+v", readiness.Checks[:2])
+	}
+	if r.Liveness(context.Background()).Status != StatusOK {
+		t.Errorf("expected liveness to be unaffected")
+	}
+}
+
+// Тестирование кэширования результатов проверок.
+func TestCaching(t *testing.T) {
+	var calls atomic.Int32
+	r := New(time.Hour)
+	r.Register(Check{Name: "db", Func: check(&calls, nil)})
+
+	for i := 0; i < 3; i++ {
+		r.Readiness(context.Background())
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call within the cache TTL, got %d", calls.Load())
+	}
+
+	// Повторная регистрация сбрасывает кэш проверки.
+	r.Register(Check{Name: "db", Func: check(&calls, nil)})
+	r.Readiness(context.Background())
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls after re-registration, got %d", calls.Load())
+	}
+
+	// Без кэширования проверка выполняется при каждом запросе.
+	calls.Store(0)
+	r = New(0)
+	r.Register(Check{Name: "db", Func: check(&calls, nil)})
+	r.Readiness(context.Background())
+	r.Readiness(context.Background())
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls without caching, got %d", calls.Load())
+	}
+}
+
+// Тестирование тайм-аута, паники и отмены запроса во время проверки.
+func TestExecute(t *testing.T) {
+	r := New(time.Hour)
+	r.Register(Check{Name: "slow", Timeout: 10 * time.Millisecond, Func: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	r.Register(Check{Name: "panic", Func: func(ctx context.Context) error {
+		panic("boom")
+	}})
+
+	report := r.Readiness(context.Background())
+	if report.Checks[0].Error != "check panicked" || report.Checks[1].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("unexpected results %+v", report.Checks)
+	}
+
+	// Отмена запроса не прерывает проверку, а ее результат не кэшируется.
+	var calls atomic.Int32
+	r = New(time.Hour)
+	r.Register(Check{Name: "db", Func: func(ctx context.Context) error {
+		calls.Add(1)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		}
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(5*time.Millisecond, cancel)
+
+	report = r.Readiness(ctx)
+	if report.Status != StatusOK {
+		t.Errorf("expected the check to finish despite the cancelled request, got %+v", report.Checks)
+	}
+
+	r.Readiness(context.Background())
+	if calls.Load() != 2 {
+		t.Errorf("expected the result of the cancelled request not to be cached, got %d calls", calls.Load())
+	}
+}
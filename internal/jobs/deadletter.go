@@ -0,0 +1,180 @@
+//Код предоставляет хранилище задач, которые не удалось выполнить ни с одной попытки (dead-letter).
+//Записи хранятся в памяти, и их можно просмотреть и снова поставить в очередь.
+
+package jobs
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNotFound возвращается, если в хранилище нет задачи с указанным идентификатором.
+var ErrNotFound = errors.New("jobs: dead letter not found")
+
+// Attempt описывает неудачную попытку выполнения задачи.
+type Attempt struct {
+	StartedAt time.Time `json:"started_at"`
+	Error     string    `json:"error"`
+	Stack     string    `json:"stack,omitempty"` // Стек вызовов, если задача завершилась паникой.
+}
+
+// DeadLetter описывает задачу, которая не была выполнена после всех попыток.
+type DeadLetter struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Attempts []Attempt `json:"attempts"`
+	FailedAt time.Time `json:"failed_at"`
+
+	seq uint64
+	job Job
+}
+
+// LastError возвращает ошибку последней попытки.
+func (dl DeadLetter) LastError() string {
+	if len(dl.Attempts) == 0 {
+		return ""
+	}
+	return dl.Attempts[len(dl.Attempts)-1].Error
+}
+
+// DeadLetters хранит неудавшиеся задачи в памяти. При заполнении хранилища самые старые записи удаляются.
+// Методы безопасны для одновременного использования из нескольких горутин и для nil-хранилища.
+type DeadLetters struct {
+	mu       sync.Mutex
+	capacity int
+	items    []DeadLetter // Упорядочены по возрастанию seq.
+	seq      uint64
+	dropped  int64
+}
+
+// NewDeadLetters создает хранилище, вмещающее не более capacity задач.
+func NewDeadLetters(capacity int) *DeadLetters {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &DeadLetters{capacity: capacity}
+}
+
+// List возвращает задачи в хранилище, начиная с последней.
+func (d *DeadLetters) List() []DeadLetter {
+	if d == nil {
+		return []DeadLetter{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	list := make([]DeadLetter, len(d.items))
+	for i, dl := range d.items {
+		list[len(list)-1-i] = dl
+	}
+	return list
+}
+
+// Get возвращает задачу по идентификатору.
+func (d *DeadLetters) Get(id string) (DeadLetter, bool) {
+	if d == nil {
+		return DeadLetter{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.find(id)
+	if !ok {
+		return DeadLetter{}, false
+	}
+	return d.items[i], true
+}
+
+// Len возвращает число задач в хранилище.
+func (d *DeadLetters) Len() int {
+	if d == nil {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.items)
+}
+
+// Dropped возвращает число записей, удаленных из-за заполнения хранилища.
+func (d *DeadLetters) Dropped() int64 {
+	if d == nil {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.dropped
+}
+
+// add сохраняет неудавшуюся задачу и возвращает запись о ней.
+func (d *DeadLetters) add(job Job, attempts []Attempt) DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seq++
+	dl := DeadLetter{
+		ID:       strconv.FormatUint(d.seq, 10),
+		Name:     job.Name,
+		Attempts: attempts,
+		FailedAt: time.Now(),
+		seq:      d.seq,
+		job:      job,
+	}
+
+	if len(d.items) >= d.capacity {
+		d.items = d.items[1:]
+		d.dropped++
+	}
+	d.items = append(d.items, dl)
+
+	return dl
+}
+
+// remove удаляет задачу из хранилища и возвращает ее.
+func (d *DeadLetters) remove(id string) (DeadLetter, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i, ok := d.find(id)
+	if !ok {
+		return DeadLetter{}, false
+	}
+
+	dl := d.items[i]
+	d.items = append(d.items[:i:i], d.items[i+1:]...)
+	return dl, true
+}
+
+// restore возвращает удаленную задачу на прежнее место, если ее не удалось снова поставить в очередь.
+// Если за это время хранилище заполнилось, как и в add, удаляется самая старая запись.
+func (d *DeadLetters) restore(dl DeadLetter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := sort.Search(len(d.items), func(i int) bool { return d.items[i].seq > dl.seq })
+	d.items = append(d.items[:i], append([]DeadLetter{dl}, d.items[i:]...)...)
+
+	if len(d.items) > d.capacity {
+		d.items = d.items[1:]
+		d.dropped++
+	}
+}
+
+// find возвращает индекс задачи с идентификатором id. Вызывается с захваченным мьютексом.
+func (d *DeadLetters) find(id string) (int, bool) {
+	seq, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	i := sort.Search(len(d.items), func(i int) bool { return d.items[i].seq >= seq })
+	return i, i < len(d.items) && d.items[i].seq == seq
+}
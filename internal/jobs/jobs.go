@@ -0,0 +1,427 @@
+//Пакет jobs выполняет фоновые задачи в пуле с ограниченным числом воркеров. Задачи ставятся в очередь
+//ограниченной емкости; при заполнении очереди постановка либо ожидает освобождения места, либо сразу
+//отклоняется. Каждая задача выполняется с тайм-аутом и контекстом, который отменяется при завершении работы.
+//Задачи, завершившиеся ошибкой, повторяются по политике повторных попыток: после задержки они снова ставятся
+//в очередь, не занимая воркер во время ожидания. После последней неудачной попытки задачи сохраняются
+//в хранилище неудавшихся задач, откуда их можно снова поставить в очередь.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrQueueFull возвращается Submit, если очередь заполнена и место в ней не освободилось.
+	ErrQueueFull = errors.New("jobs: queue is full")
+
+	// ErrClosed возвращается Submit после начала завершения работы пула.
+	ErrClosed = errors.New("jobs: pool is shut down")
+)
+
+// Overflow определяет поведение Submit при заполненной очереди.
+type Overflow int
+
+const (
+	// Block - ожидать освобождения места в очереди не дольше Options.EnqueueTimeout.
+	Block Overflow = iota
+	// Reject - сразу вернуть ErrQueueFull.
+	Reject
+)
+
+// String возвращает название поведения: "block" или "reject".
+func (o Overflow) String() string {
+	if o == Reject {
+		return "reject"
+	}
+	return "block"
+}
+
+// MarshalText реализует encoding.TextMarshaler.
+func (o Overflow) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText реализует encoding.TextUnmarshaler, чтобы поведение можно было задать в конфигурации.
+func (o *Overflow) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "block":
+		*o = Block
+	case "reject":
+		*o = Reject
+	default:
+		return errors.New("must be block or reject")
+	}
+	return nil
+}
+
+// Job описывает фоновую задачу.
+type Job struct {
+	Name    string                          // Название задачи для логов.
+	Func    func(ctx context.Context) error // Функция задачи. Должна завершаться при отмене ctx.
+	Timeout time.Duration                   // Тайм-аут выполнения одной попытки; по умолчанию Options.Timeout.
+	Retry   *RetryPolicy                    // Политика повторных попыток; по умолчанию Options.Retry.
+}
+
+// PanicError - ошибка задачи, завершившейся паникой.
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Options содержит параметры пула.
+type Options struct {
+	Workers        int           // Число воркеров; по умолчанию 1.
+	QueueSize      int           // Емкость очереди задач, ожидающих воркера.
+	Overflow       Overflow      // Поведение при заполненной очереди.
+	EnqueueTimeout time.Duration // Максимальное ожидание места в очереди для Block; 0 - без ограничения.
+	Timeout        time.Duration // Тайм-аут выполнения задачи по умолчанию; 0 - без ограничения.
+	Retry          RetryPolicy   // Политика повторных попыток по умолчанию.
+	DeadLetterSize int           // Емкость хранилища неудавшихся задач; 0 - задачи не сохраняются.
+
+	// OnRetry вызывается перед повторной попыткой с номером неудавшейся попытки и задержкой перед следующей.
+	OnRetry func(job Job, attempt int, delay time.Duration, err error)
+	// OnError вызывается для задачи, завершившейся ошибкой после всех попыток, с ошибкой последней попытки.
+	OnError func(job Job, attempts int, err error)
+}
+
+// Stats содержит метрики пула.
+type Stats struct {
+	Workers       int   `json:"workers"`
+	QueueCapacity int   `json:"queue_capacity"`
+	Queued        int   `json:"queued"`    // Задачи в очереди.
+	Running       int64 `json:"running"`   // Выполняющиеся задачи.
+	Submitted     int64 `json:"submitted"` // Принятые задачи.
+	Rejected      int64 `json:"rejected"`  // Задачи, отклоненные из-за заполненной очереди.
+	Succeeded     int64 `json:"succeeded"`
+	Failed        int64 `json:"failed"`    // Задачи, завершившиеся ошибкой после всех попыток.
+	Retried       int64 `json:"retried"`   // Повторные попытки.
+	Retrying      int64 `json:"retrying"`  // Задачи, ожидающие повторной попытки.
+	TimedOut      int64 `json:"timed_out"` // Попытки, прерванные по тайм-ауту.
+	Panicked      int64 `json:"panicked"`  // Попытки, завершившиеся паникой.
+	DeadLetters   int   `json:"dead_letters"`
+}
+
+// task - задача в очереди вместе с ее неудачными попытками.
+type task struct {
+	job      Job
+	attempts []Attempt
+}
+
+// Pool выполняет задачи в фиксированном числе воркеров.
+type Pool struct {
+	opts  Options
+	queue chan task
+
+	ctx    context.Context // Контекст задач, отменяется при завершении работы.
+	cancel context.CancelFunc
+
+	mu       sync.RWMutex // Защищает признак closed от одновременной постановки задач.
+	closed   bool
+	stopping chan struct{} // Закрывается в начале завершения работы, прерывая ожидание места в очереди.
+	stopOnce sync.Once
+	pending  sync.WaitGroup // Принятые и еще не завершенные задачи, включая ожидающие повторной попытки.
+	quit     chan struct{}  // Закрывается после завершения всех принятых задач и останавливает воркеры.
+	wg       sync.WaitGroup // Воркеры.
+
+	deadLetters *DeadLetters
+
+	running, submitted, rejected, succeeded, failed, retried, retrying, timedOut, panicked atomic.Int64
+}
+
+// New создает пул и запускает его воркеры.
+func New(opts Options) *Pool {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize < 0 {
+		opts.QueueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		opts:     opts,
+		queue:    make(chan task, opts.QueueSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		stopping: make(chan struct{}),
+		quit:     make(chan struct{}),
+	}
+	if opts.DeadLetterSize > 0 {
+		p.deadLetters = NewDeadLetters(opts.DeadLetterSize)
+	}
+
+	p.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go p.worker()
+	}
+
+	return p
+}
+
+// Submit ставит задачу в очередь. Если очередь заполнена, в зависимости от Options.Overflow сразу возвращает
+// ErrQueueFull или ожидает освобождения места, пока не истечет Options.EnqueueTimeout или не будет отменен ctx.
+// Контекст ctx ограничивает только ожидание места в очереди и не передается задаче.
+func (p *Pool) Submit(ctx context.Context, job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	// Задача учитывается до отправки в очередь, чтобы воркер не завершил ее раньше учета.
+	p.pending.Add(1)
+	t := task{job: job}
+
+	// Постановка в очередь без ожидания, если есть место.
+	select {
+	case p.queue <- t:
+		p.submitted.Add(1)
+		return nil
+	default:
+	}
+
+	if p.opts.Overflow == Reject {
+		p.pending.Done()
+		p.rejected.Add(1)
+		return ErrQueueFull
+	}
+
+	// Ожидание места в очереди.
+	if p.opts.EnqueueTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.EnqueueTimeout)
+		defer cancel()
+	}
+
+	select {
+	case p.queue <- t:
+		p.submitted.Add(1)
+		return nil
+	case <-p.stopping:
+		p.pending.Done()
+		return ErrClosed
+	case <-ctx.Done():
+		p.pending.Done()
+		p.rejected.Add(1)
+		return fmt.Errorf("%w: %w", ErrQueueFull, ctx.Err())
+	}
+}
+
+// Shutdown прекращает прием задач и ожидает выполнения задач, уже поставленных в очередь, в том числе
+// ожидающих повторной попытки. Если ctx отменяется раньше, отменяет контексты выполняющихся задач,
+// отбрасывает оставшиеся в очереди и ожидающие повторной попытки задачи (для них вызывается Options.OnError)
+// и возвращает ошибку ctx, не дожидаясь завершения воркеров.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopping)
+
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+
+		// Воркеры останавливаются после завершения всех принятых задач.
+		go func() {
+			p.pending.Wait()
+			close(p.quit)
+		}()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+// Stats возвращает текущие метрики пула.
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Workers:       p.opts.Workers,
+		QueueCapacity: p.opts.QueueSize,
+		Queued:        len(p.queue),
+		Running:       p.running.Load(),
+		Submitted:     p.submitted.Load(),
+		Rejected:      p.rejected.Load(),
+		Succeeded:     p.succeeded.Load(),
+		Failed:        p.failed.Load(),
+		Retried:       p.retried.Load(),
+		Retrying:      p.retrying.Load(),
+		TimedOut:      p.timedOut.Load(),
+		Panicked:      p.panicked.Load(),
+		DeadLetters:   p.deadLetters.Len(),
+	}
+}
+
+// DeadLetters возвращает хранилище неудавшихся задач или nil, если оно отключено.
+func (p *Pool) DeadLetters() *DeadLetters {
+	return p.deadLetters
+}
+
+// Requeue удаляет задачу из хранилища неудавшихся задач и снова ставит ее в очередь с новым счетчиком
+// попыток. Если задача не найдена, возвращает ErrNotFound; если очередь ее не приняла - ошибку Submit,
+// оставляя задачу в хранилище.
+func (p *Pool) Requeue(ctx context.Context, id string) error {
+	if p.deadLetters == nil {
+		return ErrNotFound
+	}
+
+	dl, ok := p.deadLetters.remove(id)
+	if !ok {
+		return ErrNotFound
+	}
+
+	err := p.Submit(ctx, dl.job)
+	if err != nil {
+		p.deadLetters.restore(dl)
+		return err
+	}
+
+	return nil
+}
+
+// worker выполняет задачи из очереди до остановки воркеров.
+func (p *Pool) worker() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case t := <-p.queue:
+			p.process(t)
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// process выполняет очередную попытку задачи. При ошибке, допускающей повтор, задача снова ставится в очередь
+// после задержки, а воркер сразу освобождается. Задачи, оставшиеся в очереди после отмены контекста пула,
+// не выполняются и считаются неудавшимися.
+func (p *Pool) process(t task) {
+	attempt := Attempt{StartedAt: time.Now()}
+
+	err := p.ctx.Err()
+	if err == nil {
+		err = p.run(t.job)
+	}
+	if err == nil {
+		p.succeeded.Add(1)
+		p.pending.Done()
+		return
+	}
+
+	attempt.Error = err.Error()
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		attempt.Stack = panicErr.Stack
+	}
+	t.attempts = append(t.attempts, attempt)
+
+	policy := p.opts.Retry
+	if t.job.Retry != nil {
+		policy = *t.job.Retry
+	}
+	if len(t.attempts) >= policy.MaxAttempts || !policy.retryable(err) || p.ctx.Err() != nil {
+		p.fail(t, err)
+		return
+	}
+
+	delay := policy.Delay(len(t.attempts))
+	if p.opts.OnRetry != nil {
+		p.opts.OnRetry(t.job, len(t.attempts), delay, err)
+	}
+	p.retry(t, delay, err)
+}
+
+// retry снова ставит задачу в очередь после задержки delay. Ожидание выполняется в отдельной горутине,
+// чтобы задача не занимала воркер. Если контекст пула отменяется раньше, задача считается неудавшейся
+// с ошибкой err последней попытки.
+func (p *Pool) retry(t task, delay time.Duration, err error) {
+	p.retrying.Add(1)
+
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-p.ctx.Done():
+			p.retrying.Add(-1)
+			p.fail(t, err)
+			return
+		}
+
+		// Очередь не закрывается, пока задача не завершена, поэтому отправка в нее безопасна.
+		select {
+		case p.queue <- t:
+			p.retrying.Add(-1)
+			p.retried.Add(1)
+		case <-p.ctx.Done():
+			p.retrying.Add(-1)
+			p.fail(t, err)
+		}
+	}()
+}
+
+// fail сохраняет задачу, завершившуюся ошибкой err после всех попыток, в хранилище неудавшихся задач.
+func (p *Pool) fail(t task, err error) {
+	if p.deadLetters != nil {
+		p.deadLetters.add(t.job, t.attempts)
+	}
+	p.failed.Add(1)
+	if p.opts.OnError != nil {
+		p.opts.OnError(t.job, len(t.attempts), err)
+	}
+	p.pending.Done()
+}
+
+// run выполняет задачу с тайм-аутом, преобразуя панику в *PanicError.
+func (p *Pool) run(job Job) (err error) {
+	p.running.Add(1)
+	defer p.running.Add(-1)
+
+	ctx := p.ctx
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = p.opts.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if value := recover(); value != nil {
+			p.panicked.Add(1)
+			err = &PanicError{Value: value, Stack: string(debug.Stack())}
+		}
+	}()
+
+	err = job.Func(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		p.timedOut.Add(1)
+	}
+
+	return err
+}
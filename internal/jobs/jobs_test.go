@@ -0,0 +1,379 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Тестирование ограничения числа одновременно выполняющихся задач и выполнения всех принятых задач.
+func TestPoolConcurrency(t *testing.T) {
+	p := New(Options{Workers: 2, QueueSize: 10})
+
+	var running, maxRunning, done atomic.Int64
+	for i := 0; i < 10; i++ {
+		err := p.Submit(context.Background(), Job{Name: "work", Func: func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				current := maxRunning.Load()
+				if n <= current || maxRunning.CompareAndSwap(current, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			done.Add(1)
+			return nil
+		}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	err := p.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if done.Load() != 10 {
+		t.Errorf("expected 10 completed jobs, got %d", done.Load())
+	}
+	if maxRunning.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent jobs, got %d", maxRunning.Load())
+	}
+	if stats := p.Stats(); stats.Submitted != 10 || stats.Succeeded != 10 || stats.Running != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// После завершения работы задачи не принимаются.
+	err = p.Submit(context.Background(), Job{Func: func(context.Context) error { return nil }})
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+// Тестирование поведения при заполненной очереди.
+func TestPoolOverflow(t *testing.T) {
+	release := make(chan struct{})
+	blocking := Job{Func: func(context.Context) error {
+		<-release
+		return nil
+	}}
+
+	for _, overflow := range []Overflow{Reject, Block} {
+		p := New(Options{Workers: 1, QueueSize: 1, Overflow: overflow, EnqueueTimeout: 20 * time.Millisecond})
+
+		// Первая задача занимает воркер, вторая - единственное место в очереди.
+		p.Submit(context.Background(), blocking)
+		for p.Stats().Running == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		err := p.Submit(context.Background(), blocking)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", overflow, err)
+		}
+
+		start := time.Now()
+		err = p.Submit(context.Background(), blocking)
+		if !errors.Is(err, ErrQueueFull) {
+			t.Errorf("%s: expected ErrQueueFull, got %v", overflow, err)
+		}
+		if waited := time.Since(start); (overflow == Block) != (waited >= 20*time.Millisecond) {
+			t.Errorf("%s: unexpected wait %s", overflow, waited)
+		}
+		if p.Stats().Rejected != 1 {
+			t.Errorf("%s: expected 1 rejected job, got %d", overflow, p.Stats().Rejected)
+		}
+
+		release <- struct{}{}
+		release <- struct{}{}
+		p.Shutdown(context.Background())
+	}
+}
+
+// Тестирование тайм-аутов, ошибок и паник задач.
+func TestPoolFailures(t *testing.T) {
+	var mu sync.Mutex
+	failures := map[string]error{}
+	p := New(Options{Workers: 1, QueueSize: 3, Timeout: 10 * time.Millisecond, OnError: func(job Job, attempts int, err error) {
+		mu.Lock()
+		failures[job.Name] = err
+		mu.Unlock()
+	}})
+
+	p.Submit(context.Background(), Job{Name: "timeout", Func: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	p.Submit(context.Background(), Job{Name: "error", Func: func(context.Context) error {
+		return errors.New("boom")
+	}})
+	p.Submit(context.Background(), Job{Name: "panic", Func: func(context.Context) error {
+		panic("oops")
+	}})
+	p.Shutdown(context.Background())
+
+	if !errors.Is(failures["timeout"], context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", failures["timeout"])
+	}
+	if failures["error"] == nil || failures["error"].Error() != "boom" {
+		t.Errorf("expected boom, got %v", failures["error"])
+	}
+	var panicErr *PanicError
+	if !errors.As(failures["panic"], &panicErr) || panicErr.Value != "oops" || panicErr.Stack == "" {
+		t.Errorf("expected panic error, got %v", failures["panic"])
+	}
+
+	stats := p.Stats()
+	if stats.Failed != 3 || stats.TimedOut != 1 || stats.Panicked != 1 || stats.Succeeded != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// Тестирование отмены задач по истечении срока завершения работы.
+func TestPoolShutdownDeadline(t *testing.T) {
+	var dropped atomic.Int64
+	p := New(Options{Workers: 1, QueueSize: 5, OnError: func(job Job, attempts int, err error) {
+		if errors.Is(err, context.Canceled) {
+			dropped.Add(1)
+		}
+	}})
+
+	started := make(chan struct{})
+	p.Submit(context.Background(), Job{Name: "long", Func: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	p.Submit(context.Background(), Job{Name: "queued", Func: func(context.Context) error { return nil }})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := p.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// Выполняющаяся задача получает отмену контекста, задача из очереди отбрасывается.
+	for deadline := time.Now().Add(time.Second); dropped.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if dropped.Load() != 2 {
+		t.Errorf("expected 2 cancelled jobs, got %d", dropped.Load())
+	}
+}
+
+// Тестирование разбора поведения при заполненной очереди из конфигурации.
+func TestOverflowText(t *testing.T) {
+	var o Overflow
+	if err := o.UnmarshalText([]byte("reject")); err != nil || o != Reject {
+		t.Errorf("unexpected result: %v, %s", err, o)
+	}
+	if err := o.UnmarshalText([]byte("drop")); err == nil {
+		t.Errorf("expected error for unknown overflow")
+	}
+}
+
+// Тестирование повторных попыток и сохранения неудавшихся задач.
+func TestPoolRetry(t *testing.T) {
+	var retries atomic.Int64
+	p := New(Options{
+		Workers:        1,
+		QueueSize:      5,
+		Retry:          RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+		DeadLetterSize: 10,
+		OnRetry:        func(Job, int, time.Duration, error) { retries.Add(1) },
+	})
+
+	// Задача, которая выполняется со второй попытки.
+	var flakyCalls atomic.Int64
+	p.Submit(context.Background(), Job{Name: "flaky", Func: func(context.Context) error {
+		if flakyCalls.Add(1) == 1 {
+			return errors.New("temporary")
+		}
+		return nil
+	}})
+
+	// Задача, которая не выполняется ни с одной попытки.
+	var failingCalls atomic.Int64
+	failing := func(context.Context) error {
+		failingCalls.Add(1)
+		return errors.New("unavailable")
+	}
+	p.Submit(context.Background(), Job{Name: "failing", Func: failing})
+
+	// Постоянная ошибка и ошибка, которую политика задачи не считает временной, не повторяются.
+	var permanentCalls atomic.Int64
+	p.Submit(context.Background(), Job{Name: "permanent", Func: func(context.Context) error {
+		permanentCalls.Add(1)
+		return Permanent(errors.New("invalid input"))
+	}})
+	p.Submit(context.Background(), Job{
+		Name:  "not-retryable",
+		Retry: &RetryPolicy{MaxAttempts: 5, Retryable: func(err error) bool { return false }},
+		Func:  func(context.Context) error { return errors.New("bad request") },
+	})
+
+	for p.Stats().Succeeded+p.Stats().Failed < 4 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if flakyCalls.Load() != 2 || failingCalls.Load() != 3 || permanentCalls.Load() != 1 {
+		t.Errorf("unexpected calls: flaky=%d failing=%d permanent=%d", flakyCalls.Load(), failingCalls.Load(), permanentCalls.Load())
+	}
+	if stats := p.Stats(); stats.Retried != 3 || retries.Load() != 3 || stats.Failed != 3 || stats.DeadLetters != 3 {
+		t.Errorf("unexpected stats: %+v, retries=%d", stats, retries.Load())
+	}
+
+	// Неудавшиеся задачи перечисляются начиная с последней и хранят все попытки. Задача, ожидающая
+	// повторной попытки, не задерживает остальные, поэтому завершается ошибкой последней.
+	list := p.DeadLetters().List()
+	if len(list) != 3 || list[0].Name != "failing" || list[2].Name != "permanent" {
+		t.Fatalf("unexpected dead letters: %+v", list)
+	}
+	dl, ok := p.DeadLetters().Get(list[0].ID)
+	if !ok || len(dl.Attempts) != 3 || dl.LastError() != "unavailable" {
+		t.Errorf("unexpected dead letter: %+v", dl)
+	}
+
+	// Повторная постановка в очередь удаляет задачу из хранилища и выполняет ее заново.
+	err := p.Requeue(context.Background(), dl.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := p.DeadLetters().Get(dl.ID); ok {
+		t.Errorf("expected requeued job to be removed from dead letters")
+	}
+	if err := p.Requeue(context.Background(), dl.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	p.Shutdown(context.Background())
+	if failingCalls.Load() != 6 || p.DeadLetters().Len() != 3 {
+		t.Errorf("expected requeued job to run 3 more times and fail again, got calls=%d dead letters=%d", failingCalls.Load(), p.DeadLetters().Len())
+	}
+}
+
+// Тестирование выполнения задач, пока другая задача ожидает повторной попытки.
+func TestPoolRetryDoesNotBlockWorker(t *testing.T) {
+	p := New(Options{Workers: 1, QueueSize: 1, Overflow: Reject})
+
+	// Задача, которая после первой неудачи ожидает повторной попытки час.
+	var retryingCalls atomic.Int64
+	p.Submit(context.Background(), Job{
+		Name:  "retrying",
+		Retry: &RetryPolicy{MaxAttempts: 2, Backoff: time.Hour},
+		Func: func(context.Context) error {
+			retryingCalls.Add(1)
+			return errors.New("unavailable")
+		},
+	})
+	for p.Stats().Retrying == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Единственный воркер свободен и выполняет другую задачу.
+	done := make(chan struct{})
+	err := p.Submit(context.Background(), Job{Name: "other", Func: func(context.Context) error {
+		close(done)
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the job to run while another job waits for a retry")
+	}
+
+	// При истечении срока завершения работы задача, ожидающая повторной попытки, считается неудавшейся.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected shutdown to wait for the pending retry, got %v", err)
+	}
+	for p.Stats().Failed == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if stats := p.Stats(); retryingCalls.Load() != 1 || stats.Retrying != 0 || stats.Succeeded != 1 {
+		t.Errorf("unexpected stats: %+v, calls=%d", stats, retryingCalls.Load())
+	}
+}
+
+// Тестирование экспоненциальной задержки между попытками.
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 10 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 100: 10 * time.Second} {
+		if got := policy.Delay(attempt); got != want {
+			t.Errorf("attempt %d: expected %s, got %s", attempt, want, got)
+		}
+	}
+
+	// Разброс ограничен долей Jitter.
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Delay(2); got < time.Second || got > 3*time.Second {
+			t.Fatalf("expected delay within 1s-3s, got %s", got)
+		}
+	}
+}
+
+// Тестирование вытеснения старых записей из заполненного хранилища.
+func TestDeadLettersCapacity(t *testing.T) {
+	d := NewDeadLetters(2)
+	for _, name := range []string{"a", "b", "c"} {
+		d.add(Job{Name: name}, []Attempt{{Error: "failed"}})
+	}
+
+	list := d.List()
+	if len(list) != 2 || list[0].Name != "c" || list[1].Name != "b" || d.Dropped() != 1 {
+		t.Errorf("unexpected dead letters: %+v", list)
+	}
+
+	// Задача, которую не удалось снова поставить в очередь, возвращается на прежнее место.
+	dl, _ := d.remove(list[1].ID)
+	d.restore(dl)
+	if list = d.List(); list[1].ID != dl.ID {
+		t.Errorf("expected restored job to keep its position, got %+v", list)
+	}
+
+	// Если хранилище заполнилось, пока задача была удалена, вытесняется самая старая запись.
+	tests := []struct {
+		restore string // Имя возвращаемой задачи.
+		want    []string
+	}{
+		{"c", []string{"d", "c"}},
+		{"b", []string{"d", "c"}},
+	}
+
+	for _, tt := range tests {
+		d := NewDeadLetters(2)
+		for _, name := range []string{"b", "c"} {
+			d.add(Job{Name: name}, []Attempt{{Error: "failed"}})
+		}
+
+		var removed DeadLetter
+		for _, item := range d.List() {
+			if item.Name == tt.restore {
+				removed, _ = d.remove(item.ID)
+			}
+		}
+		d.add(Job{Name: "d"}, []Attempt{{Error: "failed"}})
+		d.restore(removed)
+
+		var got []string
+		for _, item := range d.List() {
+			got = append(got, item.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") || d.Dropped() != 1 {
+			t.Errorf("restore %s: expected %v with 1 dropped, got %v with %d dropped", tt.restore, tt.want, got, d.Dropped())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//Код описывает политику повторных попыток задач: число попыток, экспоненциальную задержку со случайным
+//разбросом и классификацию ошибок на временные и постоянные.
+
+package jobs
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy определяет, сколько раз и с какой задержкой повторяется задача, завершившаяся ошибкой.
+type RetryPolicy struct {
+	MaxAttempts int                  // Общее число попыток, включая первую; 1 - без повторов.
+	Backoff     time.Duration        // Задержка перед второй попыткой; удваивается перед каждой следующей.
+	MaxBackoff  time.Duration        // Максимальная задержка; 0 - без ограничения.
+	Jitter      float64              // Доля случайного разброса задержки от 0 до 1, например 0.2 - ±20%.
+	Retryable   func(err error) bool // Определяет, можно ли повторить задачу после ошибки; по умолчанию - для любой ошибки.
+}
+
+// Delay возвращает задержку перед попыткой с номером attempt+1 после неудачной попытки attempt.
+func (rp RetryPolicy) Delay(attempt int) time.Duration {
+	delay := rp.Backoff
+	for i := 1; i < attempt && delay < math.MaxInt64/2; i++ {
+		if rp.MaxBackoff > 0 && delay >= rp.MaxBackoff {
+			break
+		}
+		delay *= 2
+	}
+	if rp.MaxBackoff > 0 && delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+
+	// Случайный разброс не дает задачам, упавшим одновременно, повторяться тоже одновременно.
+	if rp.Jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 + rp.Jitter*(2*rand.Float64()-1)))
+	}
+
+	return delay
+}
+
+// retryable сообщает, можно ли повторить задачу после ошибки err. Постоянные ошибки и паники не повторяются.
+func (rp RetryPolicy) retryable(err error) bool {
+	var permanentErr *permanentError
+	var panicErr *PanicError
+	if errors.As(err, &permanentErr) || errors.As(err, &panicErr) {
+		return false
+	}
+
+	if rp.Retryable != nil {
+		return rp.Retryable(err)
+	}
+	return true
+}
+
+// permanentError - ошибка, после которой задача не повторяется.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent помечает ошибку как постоянную: задача, вернувшая ее, не повторяется независимо от политики
+// и сразу помещается в хранилище неудавшихся задач. Для nil возвращает nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
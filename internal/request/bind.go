@@ -0,0 +1,292 @@
+//Этот код предоставляет функции для заполнения полей структуры строковыми значениями
+//(полями HTML-формы, параметрами строки запроса и пути) с преобразованием к типам полей.
+
+package request
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// msgUnknownField - сообщение об ошибке для значения, не соответствующего ни одному полю структуры.
+const msgUnknownField = "unknown field"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// FieldErrors содержит ошибки заполнения полей структуры: ключом является имя параметра, значением - сообщение.
+// Ошибки можно передать напрямую в validator.Validator:
+//
+//	for key, message := range fieldErrors {
+//		v.AddFieldError(key, message)
+//	}
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	keys := e.keys()
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s: %s", key, e[key])
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// keys возвращает отсортированный список имен параметров с ошибками.
+func (e FieldErrors) keys() []string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// bindOptions определяет правила заполнения структуры.
+type bindOptions struct {
+	tag                   string // Тег, из которого берется имя параметра.
+	splitComma            bool   // Разбивать значения срезов по запятым.
+	disallowUnknownFields bool   // Возвращать ошибку для значений без соответствующего поля.
+}
+
+// bindValues заполняет экспортируемые поля структуры dst значениями из values.
+// Имя поля берется из тега opts.tag, затем из тега json, а при их отсутствии используется имя поля.
+// Значение по умолчанию задается тегом default, формат времени - тегом layout (по умолчанию RFC 3339).
+// Все ошибки преобразования собираются и возвращаются в виде FieldErrors.
+func bindValues(dst interface{}, values map[string][]string, opts bindOptions) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		// Исключение в случае некорректного использования API.
+		panic(fmt.Sprintf("request: bind destination must be a non-nil pointer to a struct, got %T", dst))
+	}
+	rv = rv.Elem()
+
+	fieldErrors := FieldErrors{}
+
+	known := make(map[string]bool)
+	for _, field := range bindFields(rv.Type(), opts.tag) {
+		known[field.name] = true
+
+		raw := values[field.name]
+		if len(raw) == 0 {
+			// Если параметр не передан, используется значение по умолчанию из тега default.
+			defaultValue, ok := field.field.Tag.Lookup("default")
+			if !ok {
+				continue
+			}
+			raw = []string{defaultValue}
+		}
+
+		if opts.splitComma && isSliceField(field.field.Type) {
+			raw = splitComma(raw)
+		}
+
+		err := setValue(rv.FieldByIndex(field.index), raw, field.field.Tag.Get("layout"))
+		if err != nil {
+			fieldErrors[field.name] = err.Error()
+		}
+	}
+
+	if opts.disallowUnknownFields {
+		for name := range values {
+			if !known[name] {
+				fieldErrors[name] = msgUnknownField
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+
+	return nil
+}
+
+// bindField описывает поле структуры, доступное для заполнения.
+type bindField struct {
+	name  string
+	index []int
+	field reflect.StructField
+}
+
+// bindFields возвращает список полей структуры t с учетом встроенных структур.
+func bindFields(t reflect.Type, tag string) []bindField {
+	var fields []bindField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(tag) == "" {
+			for _, embedded := range bindFields(field.Type, tag) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field, tag)
+		if name == "-" {
+			continue
+		}
+
+		fields = append(fields, bindField{name: name, index: []int{i}, field: field})
+	}
+
+	return fields
+}
+
+// fieldName возвращает имя поля структуры из тега tag, затем из тега json или имя самого поля.
+func fieldName(field reflect.StructField, tag string) string {
+	for _, key := range []string{tag, "json"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// isSliceField возвращает true, если поле является срезом (или указателем на срез), кроме []byte.
+func isSliceField(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// splitComma разбивает значения, перечисленные через запятую, на отдельные элементы.
+func splitComma(raw []string) []string {
+	var values []string
+	for _, value := range raw {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
+
+// setValue преобразует строковые значения raw к типу поля v и записывает результат.
+// Возвращаемая ошибка содержит сообщение, пригодное для отправки клиенту.
+func setValue(v reflect.Value, raw []string, layout string) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		err := setValue(elem.Elem(), raw, layout)
+		if err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		slice := reflect.MakeSlice(v.Type(), len(raw), len(raw))
+		for i := range raw {
+			err := setValue(slice.Index(i), raw[i:i+1], layout)
+			if err != nil {
+				return fmt.Errorf("value %q %s", raw[i], err)
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	// Для скалярных полей используется последнее переданное значение.
+	s := raw[len(raw)-1]
+
+	switch {
+	case v.Type() == timeType:
+		t, err := parseTime(s, layout)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("must be a duration such as 1h30m or 250ms")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Slice:
+		v.SetBytes([]byte(s))
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be a boolean (true or false)")
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		v.SetFloat(f)
+
+	default:
+		return fmt.Errorf("cannot be set from a text value")
+	}
+
+	return nil
+}
+
+// parseTime разбирает время в формате layout. Если формат не задан, допускается RFC 3339 или дата в формате 2006-01-02.
+func parseTime(s, layout string) (time.Time, error) {
+	if layout != "" {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("must be a time in the format %s", layout)
+		}
+		return t, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("must be a time in the format %s or %s", time.RFC3339, time.DateOnly)
+}
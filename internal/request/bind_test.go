@@ -0,0 +1,159 @@
+package request
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Тестирование заполнения полей структуры с учетом тегов.
+func TestBindValues(t *testing.T) {
+	type Paging struct {
+		Page int `form:"page" default:"1"`
+	}
+
+	var dst struct {
+		Paging
+		Name     string `form:"name" json:"ignored"`
+		Email    string `json:"email,omitempty"`
+		Title    string // Имя берется из названия поля.
+		Secret   string `form:"-"`
+		internal string
+		Tags     []string      `form:"tags"`
+		IDs      []int         `form:"ids"`
+		Limit    *int          `form:"limit"`
+		Offset   *int          `form:"offset"`
+		Active   bool          `form:"active" default:"true"`
+		Since    time.Time     `form:"since"`
+		Day      time.Time     `form:"day" layout:"02.01.2006"`
+		Timeout  time.Duration `form:"timeout"`
+		Ratio    float64       `form:"ratio"`
+		Raw      []byte        `form:"raw"`
+	}
+
+	values := map[string][]string{
+		"name":     {"first", "alice"},
+		"email":    {"alice@example.com"},
+		"Title":    {"Dr"},
+		"Secret":   {"s3cr3t"},
+		"internal": {"x"},
+		"tags":     {"a,b", "c"},
+		"ids":      {"1, 2,,3"},
+		"limit":    {"10"},
+		"since":    {"2024-05-01T10:00:00Z"},
+		"day":      {"02.01.2024"},
+		"timeout":  {"1m30s"},
+		"ratio":    {"0.5"},
+		"raw":      {"bytes"},
+	}
+
+	err := bindValues(&dst, values, bindOptions{tag: "form", splitComma: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := map[string][2]interface{}{
+		"embedded default": {dst.Page, 1},
+		"last value":       {dst.Name, "alice"},
+		"json tag":         {dst.Email, "alice@example.com"},
+		"field name":       {dst.Title, "Dr"},
+		"skipped":          {dst.Secret, ""},
+		"unexported":       {dst.internal, ""},
+		"comma strings":    {dst.Tags, []string{"a", "b", "c"}},
+		"comma ints":       {dst.IDs, []int{1, 2, 3}},
+		"pointer":          {*dst.Limit, 10},
+		"absent pointer":   {dst.Offset, (*int)(nil)},
+		"bool default":     {dst.Active, true},
+		"RFC 3339":         {dst.Since, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		"layout":           {dst.Day, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		"duration":         {dst.Timeout, 90 * time.Second},
+		"float":            {dst.Ratio, 0.5},
+		"bytes":            {dst.Raw, []byte("bytes")},
+	}
+	for name, check := range checks {
+		if !reflect.DeepEqual(check[0], check[1]) {
+			t.Errorf("%s: expected %v, got %v", name, check[1], check[0])
+		}
+	}
+
+	// Без splitComma значения срезов не разбиваются.
+	var tags struct {
+		Tags []string `form:"tags"`
+	}
+	bindValues(&tags, map[string][]string{"tags": {"a,b"}}, bindOptions{tag: "form"})
+	if !reflect.DeepEqual(tags.Tags, []string{"a,b"}) {
+		t.Errorf("expected unsplit values, got %v", tags.Tags)
+	}
+}
+
+// Тестирование сбора ошибок преобразования и неизвестных полей.
+func TestBindValuesErrors(t *testing.T) {
+	var dst struct {
+		Age     int           `form:"age"`
+		Count   uint          `form:"count"`
+		Active  bool          `form:"active"`
+		Since   time.Time     `form:"since"`
+		Day     time.Time     `form:"day" layout:"2006/01/02"`
+		Timeout time.Duration `form:"timeout"`
+		Ratio   float32       `form:"ratio"`
+		IDs     []int         `form:"ids"`
+		Small   int8          `form:"small"`
+		Nested  struct{}      `form:"nested"`
+	}
+
+	values := map[string][]string{
+		"age":     {"thirty"},
+		"count":   {"-1"},
+		"active":  {"yes"},
+		"since":   {"yesterday"},
+		"day":     {"2024-01-02"},
+		"timeout": {"5"},
+		"ratio":   {"half"},
+		"ids":     {"1,x"},
+		"small":   {"1000"},
+		"nested":  {"{}"},
+		"extra":   {"1"},
+	}
+
+	err := bindValues(&dst, values, bindOptions{tag: "form", splitComma: true, disallowUnknownFields: true})
+
+	var fieldErrors FieldErrors
+	if !errors.As(err, &fieldErrors) {
+		t.Fatalf("expected FieldErrors, got %v", err)
+	}
+
+	want := FieldErrors{
+		"age":     "must be an integer",
+		"count":   "must be a positive integer",
+		"active":  "must be a boolean (true or false)",
+		"since":   "must be a time in the format 2006-01-02T15:04:05Z07:00 or 2006-01-02",
+		"day":     "must be a time in the format 2006/01/02",
+		"timeout": "must be a duration such as 1h30m or 250ms",
+		"ratio":   "must be a number",
+		"ids":     `value "x" must be an integer`,
+		"small":   "must be an integer",
+		"nested":  "cannot be set from a text value",
+		"extra":   msgUnknownField,
+	}
+	if !reflect.DeepEqual(fieldErrors, want) {
+		t.Errorf("unexpected errors:\n got: %v\nwant: %v", fieldErrors, want)
+	}
+
+	// Ключи в тексте ошибки отсортированы.
+	if got := (FieldErrors{"b": "x", "a": "y"}).Error(); got != "a: y; b: x" {
+		t.Errorf("unexpected error text %q", got)
+	}
+}
+
+// Тестирование исключения при некорректном назначении.
+func TestBindValuesInvalidDestination(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for a non-pointer destination")
+		}
+	}()
+
+	var dst struct{}
+	bindValues(dst, nil, bindOptions{tag: "form"})
+}
@@ -0,0 +1,108 @@
+//Этот код предоставляет функции для декодирования CBOR-тела HTTP-запроса в структуру
+//с той же обработкой ошибок, что и для JSON.
+
+package request
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	// cborDecMode - режим декодирования CBOR по умолчанию.
+	cborDecMode = mustDecMode(cbor.DecOptions{})
+
+	// cborStrictDecMode - режим декодирования CBOR, запрещающий неизвестные поля.
+	cborStrictDecMode = mustDecMode(cbor.DecOptions{ExtraReturnErrors: cbor.ExtraDecErrorUnknownField})
+)
+
+// mustDecMode создает режим декодирования CBOR. Ошибка означает некорректные параметры в коде,
+// поэтому вызывает исключение при инициализации пакета.
+func mustDecMode(opts cbor.DecOptions) cbor.DecMode {
+	decMode, err := opts.DecMode()
+	if err != nil {
+		panic(fmt.Sprintf("request: invalid CBOR decoding options: %v", err))
+	}
+	return decMode
+}
+
+// DecodeCBOR декодирует CBOR-тело HTTP-запроса в структуру, переданную в параметре dst.
+func DecodeCBOR(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeCBOR(w, r, dst, false)
+}
+
+// DecodeCBORStrict выполняет строгую декодировку CBOR-тела HTTP-запроса.
+// Строгая декодировка запрещает неизвестные поля.
+func DecodeCBORStrict(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeCBOR(w, r, dst, true)
+}
+
+func decodeCBOR(w http.ResponseWriter, r *http.Request, dst interface{}, disallowUnknownFields bool) error {
+	maxBytes := limitBody(w, r)
+
+	decMode := cborDecMode
+	if disallowUnknownFields {
+		decMode = cborStrictDecMode
+	}
+	dec := decMode.NewDecoder(r.Body)
+
+	err := dec.Decode(dst)
+	if err != nil {
+		var syntaxError *cbor.SyntaxError
+		var unmarshalTypeError *cbor.UnmarshalTypeError
+		var unknownFieldError *cbor.UnknownFieldError
+		var invalidUnmarshalError *cbor.InvalidUnmarshalError
+
+		switch {
+		case errors.As(err, &syntaxError):
+			return fmt.Errorf("body contains badly-formed CBOR (%s)", syntaxError.Error())
+
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return errors.New("body contains badly-formed CBOR")
+
+		case errors.As(err, &unmarshalTypeError):
+			if unmarshalTypeError.StructFieldName != "" {
+				// Имя поля передается в виде "Тип.поле", клиенту сообщается только имя поля.
+				fieldName := unmarshalTypeError.StructFieldName
+				if i := strings.LastIndex(fieldName, "."); i >= 0 {
+					fieldName = fieldName[i+1:]
+				}
+				return fmt.Errorf("body contains incorrect CBOR type for field %q", fieldName)
+			}
+			return fmt.Errorf("body contains incorrect CBOR type (%s)", unmarshalTypeError.CBORType)
+
+		case errors.Is(err, io.EOF):
+			return errors.New("body must not be empty")
+
+		case errors.As(err, &unknownFieldError):
+			return fmt.Errorf("body contains unknown key (at map index %d)", unknownFieldError.Index)
+
+		case isTooLarge(err):
+			return errTooLarge(maxBytes)
+
+		case errors.As(err, &invalidUnmarshalError):
+			// Исключение в случае некорректного использования API.
+			panic(err)
+
+		default:
+			return err
+		}
+	}
+
+	// Проверяем, что в теле содержится только одно значение.
+	var extra cbor.RawMessage
+	err = dec.Decode(&extra)
+	if !errors.Is(err, io.EOF) {
+		if err != nil && isTooLarge(err) {
+			return errTooLarge(maxBytes)
+		}
+		return errors.New("body must only contain a single CBOR value")
+	}
+
+	return nil
+}
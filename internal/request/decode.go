@@ -0,0 +1,116 @@
+//Этот код предоставляет функцию Decode, которая выбирает декодер тела HTTP-запроса по заголовку Content-Type
+//(JSON, XML, MessagePack, CBOR, form-urlencoded и multipart) по аналогии с согласованием формата ответа.
+
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBytes - максимальный размер тела запроса в байтах, если для запроса не задан другой предел.
+const DefaultMaxBytes = 1_048_576
+
+// contextKey - тип ключей контекста запроса, используемых пакетом request.
+type contextKey string
+
+// maxBytesContextKey - ключ контекста, под которым хранится предел размера тела запроса.
+const maxBytesContextKey = contextKey("maxBytes")
+
+// UnsupportedMediaTypeError возвращается, если тип содержимого тела запроса не поддерживается.
+// Обработчики должны отвечать на эту ошибку статусом 415 Unsupported Media Type.
+type UnsupportedMediaTypeError struct {
+	MediaType string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("body content type %q is not supported", e.MediaType)
+}
+
+// Decode декодирует тело HTTP-запроса в структуру, переданную в параметре dst, выбирая формат по заголовку Content-Type.
+// Если заголовок Content-Type отсутствует, тело декодируется как JSON.
+// Для неподдерживаемых типов содержимого возвращается *UnsupportedMediaTypeError.
+func Decode(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decode(w, r, dst, false)
+}
+
+// DecodeStrict работает так же, как Decode, но запрещает неизвестные поля в теле запроса.
+func DecodeStrict(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decode(w, r, dst, true)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, dst interface{}, disallowUnknownFields bool) error {
+	// Отсутствие заголовка Content-Type трактуется как JSON для обратной совместимости.
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return decodeJSON(w, r, dst, disallowUnknownFields)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &UnsupportedMediaTypeError{MediaType: contentType}
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return decodeJSON(w, r, dst, disallowUnknownFields)
+
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return decodeXML(w, r, dst, disallowUnknownFields)
+
+	case mediaType == "application/msgpack" || mediaType == "application/x-msgpack" || mediaType == "application/vnd.msgpack":
+		return decodeMsgpack(w, r, dst, disallowUnknownFields)
+
+	case mediaType == "application/cbor" || strings.HasSuffix(mediaType, "+cbor"):
+		return decodeCBOR(w, r, dst, disallowUnknownFields)
+
+	case mediaType == "application/x-www-form-urlencoded":
+		return decodeForm(w, r, dst, disallowUnknownFields)
+
+	case mediaType == "multipart/form-data":
+		return decodeMultipartForm(w, r, dst, disallowUnknownFields)
+
+	default:
+		return &UnsupportedMediaTypeError{MediaType: mediaType}
+	}
+}
+
+// WithMaxBytes возвращает копию запроса, для которой предел размера тела равен n байтам.
+// Используется в middleware для установки глобального предела и его переопределения для отдельных маршрутов.
+func WithMaxBytes(r *http.Request, n int64) *http.Request {
+	ctx := context.WithValue(r.Context(), maxBytesContextKey, n)
+	return r.WithContext(ctx)
+}
+
+// MaxBytes возвращает предел размера тела запроса, установленный с помощью WithMaxBytes, или DefaultMaxBytes.
+func MaxBytes(r *http.Request) int64 {
+	n, ok := r.Context().Value(maxBytesContextKey).(int64)
+	if !ok || n <= 0 {
+		return DefaultMaxBytes
+	}
+
+	return n
+}
+
+// limitBody ограничивает размер тела запроса, чтобы предотвратить атаки на переполнение буфера,
+// и возвращает установленный предел.
+func limitBody(w http.ResponseWriter, r *http.Request) int64 {
+	maxBytes := MaxBytes(r)
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	return maxBytes
+}
+
+// isTooLarge возвращает true, если ошибка вызвана превышением максимального размера тела запроса.
+func isTooLarge(err error) bool {
+	var maxBytesError *http.MaxBytesError
+	return errors.As(err, &maxBytesError) || err.Error() == "http: request body too large"
+}
+
+// errTooLarge возвращает ошибку о превышении максимального размера тела запроса.
+func errTooLarge(maxBytes int64) error {
+	return fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+}
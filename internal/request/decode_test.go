@@ -0,0 +1,234 @@
+package request
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// testInput - структура, в которую декодируются тела запросов в тестах.
+type testInput struct {
+	Name string `json:"name" xml:"name" form:"name" cbor:"name"`
+	Age  int    `json:"age" xml:"age" form:"age" cbor:"age"`
+}
+
+// newBodyRequest создает POST-запрос с заданным заголовком Content-Type и телом.
+func newBodyRequest(contentType string, body []byte) *http.Request {
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+// mustMarshal возвращает результат кодирования или завершает тест при ошибке.
+func mustMarshal(t *testing.T, fn func(interface{}) ([]byte, error), v interface{}) []byte {
+	t.Helper()
+
+	data, err := fn(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Тестирование выбора декодера по заголовку Content-Type.
+func TestDecodeContentNegotiation(t *testing.T) {
+	value := map[string]interface{}{"name": "alice", "age": 30}
+	jsonBody := []byte(`{"name": "alice", "age": 30}`)
+	xmlBody := []byte(`<input><name>alice</name><age>30</age></input>`)
+
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"", jsonBody},
+		{"application/json", jsonBody},
+		{"application/problem+json; charset=utf-8", jsonBody},
+		{"application/xml", xmlBody},
+		{"text/xml; charset=utf-8", xmlBody},
+		{"application/atom+xml", xmlBody},
+		{"application/msgpack", mustMarshal(t, msgpack.Marshal, value)},
+		{"application/x-msgpack", mustMarshal(t, msgpack.Marshal, value)},
+		{"application/cbor", mustMarshal(t, cbor.Marshal, value)},
+		{"application/x-www-form-urlencoded", []byte("name=alice&age=30")},
+	}
+
+	for _, tt := range tests {
+		var dst testInput
+		err := Decode(httptest.NewRecorder(), newBodyRequest(tt.contentType, tt.body), &dst)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.contentType, err)
+			continue
+		}
+		if dst != (testInput{Name: "alice", Age: 30}) {
+			t.Errorf("%q: unexpected result %+v", tt.contentType, dst)
+		}
+	}
+
+	// Неподдерживаемые и некорректные типы содержимого.
+	for contentType, want := range map[string]string{"text/plain": "text/plain", "text/plain; charset=utf-8": "text/plain", "application/": "application/"} {
+		var dst testInput
+		err := Decode(httptest.NewRecorder(), newBodyRequest(contentType, jsonBody), &dst)
+
+		var mediaTypeError *UnsupportedMediaTypeError
+		if !errors.As(err, &mediaTypeError) || mediaTypeError.MediaType != want {
+			t.Errorf("%q: expected UnsupportedMediaTypeError for %q, got %v", contentType, want, err)
+		}
+	}
+}
+
+// Тестирование запрета неизвестных полей в строгом режиме для каждого формата.
+func TestDecodeUnknownFields(t *testing.T) {
+	value := map[string]interface{}{"name": "alice", "extra": true}
+
+	tests := []struct {
+		contentType string
+		body        []byte
+		want        string
+	}{
+		{"application/json", []byte(`{"name": "alice", "extra": true}`), `body contains unknown key "extra"`},
+		{"application/xml", []byte(`<input><name>alice</name><extra>true</extra></input>`), `body contains unknown key "extra"`},
+		{"application/msgpack", mustMarshal(t, msgpack.Marshal, value), "body contains unknown key"},
+		{"application/cbor", mustMarshal(t, cbor.Marshal, value), "body contains unknown key"},
+		{"application/x-www-form-urlencoded", []byte("name=alice&extra=true"), `body contains unknown key "extra"`},
+	}
+
+	for _, tt := range tests {
+		var dst testInput
+		err := DecodeStrict(httptest.NewRecorder(), newBodyRequest(tt.contentType, tt.body), &dst)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.contentType, tt.want, err)
+		}
+
+		// Без строгого режима неизвестные поля игнорируются.
+		dst = testInput{}
+		err = Decode(httptest.NewRecorder(), newBodyRequest(tt.contentType, tt.body), &dst)
+		if err != nil || dst.Name != "alice" {
+			t.Errorf("%s: unexpected result %+v, %v", tt.contentType, dst, err)
+		}
+	}
+}
+
+// Тестирование предела размера тела запроса для каждого формата.
+func TestDecodeMaxBytes(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	value := map[string]interface{}{"name": long}
+
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"application/json", []byte(`{"name": "` + long + `"}`)},
+		{"application/xml", []byte(`<input><name>` + long + `</name></input>`)},
+		{"application/msgpack", mustMarshal(t, msgpack.Marshal, value)},
+		{"application/cbor", mustMarshal(t, cbor.Marshal, value)},
+		{"application/x-www-form-urlencoded", []byte("name=" + long)},
+	}
+
+	for _, tt := range tests {
+		var dst testInput
+		r := WithMaxBytes(newBodyRequest(tt.contentType, tt.body), 50)
+		err := Decode(httptest.NewRecorder(), r, &dst)
+		if err == nil || err.Error() != "body must not be larger than 50 bytes" {
+			t.Errorf("%s: expected size limit error, got %v", tt.contentType, err)
+		}
+	}
+
+	// Предел по умолчанию.
+	if n := MaxBytes(httptest.NewRequest("POST", "/", nil)); n != DefaultMaxBytes {
+		t.Errorf("expected default limit %d, got %d", DefaultMaxBytes, n)
+	}
+}
+
+// Тестирование сообщений об ошибках в некорректных телах запросов.
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        string
+	}{
+		{"application/json", ``, "body must not be empty"},
+		{"application/json", `{"name": "alice",}`, "body contains badly-formed JSON (at character 18)"},
+		{"application/json", `{"name": "alice"`, "body contains badly-formed JSON"},
+		{"application/json", `{"age": "thirty"}`, `body contains incorrect JSON type for field "age"`},
+		{"application/json", `["alice"]`, "body contains incorrect JSON type (at character 1)"},
+		{"application/json", `{"name": "alice"}{"name": "bob"}`, "body must only contain a single JSON value"},
+		{"application/xml", ``, "body must not be empty"},
+		{"application/xml", "<input>\n<name>alice</nam></input>", "body contains badly-formed XML (at line 2)"},
+		{"application/xml", `<input><age>thirty</age></input>`, `body contains incorrect XML value "thirty"`},
+		{"application/xml", `<input></input><input></input>`, "body must only contain a single XML value"},
+		{"application/cbor", ``, "body must not be empty"},
+		{"application/cbor", "\xa1\x63age\x65alice", `body contains incorrect CBOR type for field "age"`},
+		{"application/msgpack", ``, "body must not be empty"},
+		{"application/msgpack", "\x81\xa4name", "body contains badly-formed MessagePack"},
+		{"application/x-www-form-urlencoded", ``, "body must not be empty"},
+		{"application/x-www-form-urlencoded", `age=thirty`, `body contains incorrect value for field "age"`},
+		{"application/x-www-form-urlencoded", `name=%zz`, "body contains badly-formed form data"},
+	}
+
+	for _, tt := range tests {
+		var dst testInput
+		err := Decode(httptest.NewRecorder(), newBodyRequest(tt.contentType, []byte(tt.body)), &dst)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s %q: expected %q, got %v", tt.contentType, tt.body, tt.want, err)
+		}
+	}
+}
+
+// Тестирование декодирования multipart-формы с загруженными файлами.
+func TestDecodeMultipartForm(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("name", "alice")
+	for _, filename := range []string{"a.txt", "b.txt"} {
+		fw, err := mw.CreateFormFile("attachments", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte("content of " + filename))
+	}
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	fw.Write([]byte("png"))
+	mw.Close()
+
+	var dst struct {
+		Name        string                  `form:"name"`
+		Avatar      *multipart.FileHeader   `form:"avatar"`
+		Attachments []*multipart.FileHeader `form:"attachments"`
+	}
+
+	err := DecodeStrict(httptest.NewRecorder(), newBodyRequest(mw.FormDataContentType(), body.Bytes()), &dst)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Name != "alice" || dst.Avatar == nil || dst.Avatar.Filename != "avatar.png" || len(dst.Attachments) != 2 {
+		t.Errorf("unexpected result %+v", dst)
+	}
+
+	// Файл в поле, не предназначенном для файлов.
+	var wrong struct {
+		Name   string `form:"name"`
+		Avatar string `form:"avatar"`
+	}
+	err = Decode(httptest.NewRecorder(), newBodyRequest(mw.FormDataContentType(), body.Bytes()), &wrong)
+	if err == nil || err.Error() != `body contains incorrect value for field "avatar"` {
+		t.Errorf("expected incorrect value error, got %v", err)
+	}
+
+	// Неизвестное поле с файлом в строгом режиме.
+	var strict struct {
+		Name string `form:"name"`
+	}
+	err = DecodeStrict(httptest.NewRecorder(), newBodyRequest(mw.FormDataContentType(), body.Bytes()), &strict)
+	if err == nil || !strings.HasPrefix(err.Error(), "body contains unknown key") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+//Этот код предоставляет функции для декодирования тела HTTP-запроса в формате
+//application/x-www-form-urlencoded и multipart/form-data в структуру.
+
+package request
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// DecodeForm декодирует тело HTTP-запроса в формате application/x-www-form-urlencoded в структуру dst.
+// Имена полей формы берутся из тега form, затем из тега json.
+func DecodeForm(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeForm(w, r, dst, false)
+}
+
+// DecodeFormStrict выполняет строгую декодировку тела формы, запрещая неизвестные поля.
+func DecodeFormStrict(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeForm(w, r, dst, true)
+}
+
+// DecodeMultipartForm декодирует тело HTTP-запроса в формате multipart/form-data в структуру dst.
+// Загруженные файлы записываются в поля типа *multipart.FileHeader и []*multipart.FileHeader.
+func DecodeMultipartForm(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeMultipartForm(w, r, dst, false)
+}
+
+// DecodeMultipartFormStrict выполняет строгую декодировку multipart-тела, запрещая неизвестные поля.
+func DecodeMultipartFormStrict(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeMultipartForm(w, r, dst, true)
+}
+
+func decodeForm(w http.ResponseWriter, r *http.Request, dst interface{}, disallowUnknownFields bool) error {
+	maxBytes := limitBody(w, r)
+
+	err := r.ParseForm()
+	if err != nil {
+		if isTooLarge(err) {
+			return errTooLarge(maxBytes)
+		}
+		return errors.New("body contains badly-formed form data")
+	}
+
+	if len(r.PostForm) == 0 {
+		return errors.New("body must not be empty")
+	}
+
+	return formError(bindValues(dst, r.PostForm, bindOptions{tag: "form", disallowUnknownFields: disallowUnknownFields}))
+}
+
+func decodeMultipartForm(w http.ResponseWriter, r *http.Request, dst interface{}, disallowUnknownFields bool) error {
+	maxBytes := limitBody(w, r)
+
+	err := r.ParseMultipartForm(maxBytes)
+	if err != nil {
+		if isTooLarge(err) || errors.Is(err, multipart.ErrMessageTooLarge) {
+			return errTooLarge(maxBytes)
+		}
+		return errors.New("body contains badly-formed multipart form data")
+	}
+
+	form := r.MultipartForm
+	if len(form.Value) == 0 && len(form.File) == 0 {
+		return errors.New("body must not be empty")
+	}
+
+	// Файлы проверяются на неизвестные поля вместе с обычными значениями формы.
+	values := make(map[string][]string, len(form.Value)+len(form.File))
+	for key, value := range form.Value {
+		values[key] = value
+	}
+	for key := range form.File {
+		if _, exists := values[key]; !exists {
+			values[key] = nil
+		}
+	}
+
+	err = bindValues(dst, values, bindOptions{tag: "form", disallowUnknownFields: disallowUnknownFields})
+	if err != nil {
+		return formError(err)
+	}
+
+	return bindFiles(dst, form.File)
+}
+
+// bindFiles записывает загруженные файлы в поля типа *multipart.FileHeader и []*multipart.FileHeader.
+func bindFiles(dst interface{}, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(dst).Elem()
+
+	for _, field := range bindFields(rv.Type(), "form") {
+		headers := files[field.name]
+		if len(headers) == 0 {
+			continue
+		}
+
+		switch field.field.Type {
+		case fileHeaderType:
+			rv.FieldByIndex(field.index).Set(reflect.ValueOf(headers[0]))
+		case fileHeaderSliceType:
+			rv.FieldByIndex(field.index).Set(reflect.ValueOf(headers))
+		default:
+			return fmt.Errorf("body contains incorrect value for field %q", field.name)
+		}
+	}
+
+	return nil
+}
+
+// formError преобразует ошибку заполнения структуры значениями формы в понятное клиенту сообщение.
+// Как и для других форматов, сообщается только о первой ошибке.
+func formError(err error) error {
+	var fieldErrors FieldErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	key := fieldErrors.keys()[0]
+	if fieldErrors[key] == msgUnknownField {
+		return fmt.Errorf("body contains unknown key %q", key)
+	}
+
+	return fmt.Errorf("body contains incorrect value for field %q", key)
+}
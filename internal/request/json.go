@@ -26,8 +26,7 @@ func DecodeJSONStrict(w http.ResponseWriter, r *http.Request, dst interface{}) e
 
 func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}, disallowUnknownFields bool) error {
 	// Ограничиваем размер JSON-тела, чтобы предотвратить атаки на переполнение буфера.
-	maxBytes := 1_048_576
-	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+	maxBytes := limitBody(w, r)
 
 	dec := json.NewDecoder(r.Body)
 
@@ -39,47 +38,55 @@ func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}, disallo
 	// Декодируем JSON-тело в переданную структуру.
 	err := dec.Decode(dst)
 	if err != nil {
-		var syntaxError *json.SyntaxError
-		var unmarshalTypeError *json.UnmarshalTypeError
-		var invalidUnmarshalError *json.InvalidUnmarshalError
+		return jsonError(err, maxBytes)
+	}
+
+	// Проверяем, что в JSON-теле содержится только одно значение.
+	err = dec.Decode(&struct{}{})
+	if !errors.Is(err, io.EOF) {
+		if err != nil && isTooLarge(err) {
+			return errTooLarge(maxBytes)
+		}
+		return errors.New("body must only contain a single JSON value")
+	}
+
+	return nil
+}
 
-		switch {
-		case errors.As(err, &syntaxError):
-			return fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
+// jsonError преобразует ошибку декодирования JSON в понятное клиенту сообщение.
+func jsonError(err error, maxBytes int64) error {
+	var syntaxError *json.SyntaxError
+	var unmarshalTypeError *json.UnmarshalTypeError
+	var invalidUnmarshalError *json.InvalidUnmarshalError
 
-		case errors.Is(err, io.ErrUnexpectedEOF):
-			return errors.New("body contains badly-formed JSON")
+	switch {
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
 
-		case errors.As(err, &unmarshalTypeError):
-			if unmarshalTypeError.Field != "" {
-				return fmt.Errorf("body contains incorrect JSON type for field %q", unmarshalTypeError.Field)
-			}
-			return fmt.Errorf("body contains incorrect JSON type (at character %d)", unmarshalTypeError.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("body contains badly-formed JSON")
 
-		case errors.Is(err, io.EOF):
-			return errors.New("body must not be empty")
+	case errors.As(err, &unmarshalTypeError):
+		if unmarshalTypeError.Field != "" {
+			return fmt.Errorf("body contains incorrect JSON type for field %q", unmarshalTypeError.Field)
+		}
+		return fmt.Errorf("body contains incorrect JSON type (at character %d)", unmarshalTypeError.Offset)
 
-		case strings.HasPrefix(err.Error(), "json: unknown field "):
-			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
-			return fmt.Errorf("body contains unknown key %s", fieldName)
+	case errors.Is(err, io.EOF):
+		return errors.New("body must not be empty")
 
-		case err.Error() == "http: request body too large":
-			return fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return fmt.Errorf("body contains unknown key %s", fieldName)
 
-		case errors.As(err, &invalidUnmarshalError):
-			// Исключение в случае некорректного использования API.
-			panic(err)
+	case isTooLarge(err):
+		return errTooLarge(maxBytes)
 
-		default:
-			return err
-		}
-	}
+	case errors.As(err, &invalidUnmarshalError):
+		// Исключение в случае некорректного использования API.
+		panic(err)
 
-	// Проверяем, что в JSON-теле содержится только одно значение.
-	err = dec.Decode(&struct{}{})
-	if !errors.Is(err, io.EOF) {
-		return errors.New("body must only contain a single JSON value")
+	default:
+		return err
 	}
-
-	return nil
 }
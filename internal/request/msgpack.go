@@ -0,0 +1,94 @@
+//Этот код предоставляет функции для декодирования MessagePack-тела HTTP-запроса в структуру
+//с той же обработкой ошибок, что и для JSON.
+
+package request
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// DecodeMsgpack декодирует MessagePack-тело HTTP-запроса в структуру, переданную в параметре dst.
+func DecodeMsgpack(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeMsgpack(w, r, dst, false)
+}
+
+// DecodeMsgpackStrict выполняет строгую декодировку MessagePack-тела HTTP-запроса.
+// Строгая декодировка запрещает неизвестные поля.
+func DecodeMsgpackStrict(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeMsgpack(w, r, dst, true)
+}
+
+func decodeMsgpack(w http.ResponseWriter, r *http.Request, dst interface{}, disallowUnknownFields bool) error {
+	maxBytes := limitBody(w, r)
+
+	// Пустое тело определяется заранее: декодер возвращает io.EOF и для тела, оборванного посреди значения.
+	br := bufio.NewReader(r.Body)
+	_, err := br.Peek(1)
+	if err != nil {
+		return msgpackError(err, maxBytes)
+	}
+
+	dec := msgpack.NewDecoder(br)
+	dec.DisallowUnknownFields(disallowUnknownFields)
+
+	// Используем имена полей из тегов json, если тег msgpack не указан.
+	dec.SetCustomStructTag("json")
+
+	err = dec.Decode(dst)
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return msgpackError(err, maxBytes)
+	}
+
+	// Проверяем, что в теле содержится только одно значение.
+	err = dec.Skip()
+	if !errors.Is(err, io.EOF) {
+		if err != nil && isTooLarge(err) {
+			return errTooLarge(maxBytes)
+		}
+		return errors.New("body must only contain a single MessagePack value")
+	}
+
+	return nil
+}
+
+// msgpackError преобразует ошибку декодирования MessagePack в понятное клиенту сообщение.
+func msgpackError(err error, maxBytes int64) error {
+	message := err.Error()
+
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("body contains badly-formed MessagePack")
+
+	case errors.Is(err, io.EOF):
+		return errors.New("body must not be empty")
+
+	case isTooLarge(err):
+		return errTooLarge(maxBytes)
+
+	case strings.HasPrefix(message, "msgpack: unknown field "):
+		return fmt.Errorf("body contains unknown key %s", strings.TrimPrefix(message, "msgpack: unknown field "))
+
+	case strings.HasPrefix(message, "msgpack: Decode("):
+		// Исключение в случае некорректного использования API.
+		panic(err)
+
+	case strings.HasPrefix(message, "msgpack: invalid code") || strings.HasPrefix(message, "msgpack: unsupported code"):
+		return fmt.Errorf("body contains incorrect MessagePack type (%s)", strings.TrimPrefix(message, "msgpack: "))
+
+	case strings.HasPrefix(message, "msgpack: "):
+		return errors.New("body contains badly-formed MessagePack")
+
+	default:
+		return err
+	}
+}
@@ -0,0 +1,79 @@
+//Этот код предоставляет тип Optional для полей частичного обновления, который различает
+//отсутствующее поле, поле со значением null и поле с заданным значением.
+
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Optional хранит значение поля JSON с тремя состояниями: поле отсутствует (Set == false),
+// поле явно равно null (Set == true, Null == true) или поле задано (Set == true, Null == false).
+// Работает с json.Unmarshal, request.DecodeJSON и request.DecodeJSONStrict.
+type Optional[T any] struct {
+	Value T    // Значение поля, если оно задано.
+	Set   bool // Поле присутствует в JSON (в том числе со значением null).
+	Null  bool // Поле явно равно null.
+}
+
+// Some возвращает Optional с заданным значением.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true}
+}
+
+// UnmarshalJSON вызывается только для полей, присутствующих в JSON, поэтому отмечает поле как заданное.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		o.Value = zero
+		o.Null = true
+		return nil
+	}
+
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON кодирует заданное значение, а отсутствующее или null-значение - как null. Метод не может
+// исключить поле из объекта, а omitempty не действует на поля-структуры, поэтому после кодирования
+// и повторного декодирования отсутствующее поле становится null. Optional предназначен для разбора запросов;
+// если в ответе нужно сохранить различие, документ следует собирать явно, например в map, по значению Set.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.HasValue() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// HasValue возвращает true, если поле задано и не равно null.
+func (o Optional[T]) HasValue() bool {
+	return o.Set && !o.Null
+}
+
+// Get возвращает значение и true, если поле задано и не равно null.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.HasValue()
+}
+
+// OrElse возвращает значение поля или fallback, если поле не задано или равно null.
+func (o Optional[T]) OrElse(fallback T) T {
+	if o.HasValue() {
+		return o.Value
+	}
+	return fallback
+}
+
+// Check применяет функцию проверки (например, validator.NotBlank) только к заданному значению.
+// Если поле отсутствует или равно null, возвращается true.
+func (o Optional[T]) Check(fn func(T) bool) bool {
+	return !o.HasValue() || fn(o.Value)
+}
+
+// OptionalValue возвращает значение и true, если поле задано и не равно null.
+// Используется validator.ValidateStruct, чтобы применять правила тегов validate только к заданным значениям.
+func (o Optional[T]) OptionalValue() (interface{}, bool) {
+	return o.Value, o.HasValue()
+}
@@ -0,0 +1,121 @@
+package request
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+// testOptionalInput - структура частичного обновления для тестов Optional.
+type testOptionalInput struct {
+	Name Optional[string] `json:"name"`
+	Age  Optional[int]    `json:"age"`
+}
+
+// Тестирование различения отсутствующего поля, null и заданного значения при декодировании.
+func TestOptionalDecode(t *testing.T) {
+	decoders := map[string]func(body string, dst interface{}) error{
+		"json.Unmarshal": func(body string, dst interface{}) error {
+			return json.Unmarshal([]byte(body), dst)
+		},
+		"DecodeJSON": func(body string, dst interface{}) error {
+			return DecodeJSON(httptest.NewRecorder(), newBodyRequest("application/json", []byte(body)), dst)
+		},
+		"DecodeJSONStrict": func(body string, dst interface{}) error {
+			return DecodeJSONStrict(httptest.NewRecorder(), newBodyRequest("application/json", []byte(body)), dst)
+		},
+	}
+
+	tests := []struct {
+		body string
+		name Optional[string]
+		age  Optional[int]
+	}{
+		{`{}`, Optional[string]{}, Optional[int]{}},
+		{`{"name": null, "age": null}`, Optional[string]{Set: true, Null: true}, Optional[int]{Set: true, Null: true}},
+		{`{"name": "alice", "age": 0}`, Some("alice"), Some(0)},
+		{`{"name": ""}`, Some(""), Optional[int]{}},
+	}
+
+	for decoder, decode := range decoders {
+		for _, tt := range tests {
+			var input testOptionalInput
+			err := decode(tt.body, &input)
+			if err != nil {
+				t.Fatalf("%s %s: unexpected error: %v", decoder, tt.body, err)
+			}
+			if input.Name != tt.name || input.Age != tt.age {
+				t.Errorf("%s %s: expected %+v, %+v, got %+v, %+v", decoder, tt.body, tt.name, tt.age, input.Name, input.Age)
+			}
+		}
+
+		// Значение неверного типа - ошибка декодирования.
+		var input testOptionalInput
+		err := decode(`{"age": "ten"}`, &input)
+		if err == nil {
+			t.Errorf("%s: expected error for a value of the wrong type", decoder)
+		}
+	}
+}
+
+// Тестирование методов доступа к значению.
+func TestOptionalAccessors(t *testing.T) {
+	notBlank := func(s string) bool { return s != "" }
+
+	tests := []struct {
+		o         Optional[string]
+		hasValue  bool
+		orElse    string
+		checkPass bool
+	}{
+		{Optional[string]{}, false, "fallback", true},
+		{Optional[string]{Set: true, Null: true}, false, "fallback", true},
+		{Some("alice"), true, "alice", true},
+		{Some(""), true, "", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := tt.o.Get(); ok != tt.hasValue || tt.o.HasValue() != tt.hasValue {
+			t.Errorf("%+v: expected HasValue %v", tt.o, tt.hasValue)
+		}
+		if got := tt.o.OrElse("fallback"); got != tt.orElse {
+			t.Errorf("%+v: expected OrElse %q, got %q", tt.o, tt.orElse, got)
+		}
+		if got := tt.o.Check(notBlank); got != tt.checkPass {
+			t.Errorf("%+v: expected Check %v, got %v", tt.o, tt.checkPass, got)
+		}
+	}
+}
+
+// Тестирование кодирования Optional в JSON.
+func TestOptionalMarshal(t *testing.T) {
+	tests := []struct {
+		input testOptionalInput
+		want  string
+	}{
+		{testOptionalInput{}, `{"name":null,"age":null}`},
+		{testOptionalInput{Name: Optional[string]{Set: true, Null: true}}, `{"name":null,"age":null}`},
+		{testOptionalInput{Name: Some("alice"), Age: Some(0)}, `{"name":"alice","age":0}`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("expected %s, got %s", tt.want, data)
+		}
+	}
+
+	// Отсутствующее поле кодируется как null, поэтому после кодирования и декодирования становится null.
+	data, _ := json.Marshal(testOptionalInput{})
+	var decoded testOptionalInput
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.Name.Null {
+		t.Errorf("expected an absent field to round-trip as null, got %+v", decoded.Name)
+	}
+}
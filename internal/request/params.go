@@ -0,0 +1,32 @@
+//Этот код предоставляет функции для заполнения структур параметрами строки запроса
+//и переменными пути маршрутизатора Gorilla Mux.
+
+package request
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// DecodeQuery заполняет структуру dst параметрами строки запроса.
+// Имена параметров берутся из тега query. Поддерживаются строки, целые и вещественные числа, булевы значения,
+// time.Time (формат задается тегом layout), time.Duration, срезы (повторяющиеся параметры или значения через запятую),
+// указатели для необязательных параметров и значения по умолчанию из тега default.
+// Ошибки преобразования возвращаются в виде FieldErrors с ключами по именам параметров.
+func DecodeQuery(r *http.Request, dst interface{}) error {
+	return bindValues(dst, r.URL.Query(), bindOptions{tag: "query", splitComma: true})
+}
+
+// DecodePath заполняет структуру dst переменными пути из mux.Vars.
+// Имена переменных берутся из тега path, остальные правила такие же, как у DecodeQuery.
+func DecodePath(r *http.Request, dst interface{}) error {
+	vars := mux.Vars(r)
+
+	values := make(map[string][]string, len(vars))
+	for key, value := range vars {
+		values[key] = []string{value}
+	}
+
+	return bindValues(dst, values, bindOptions{tag: "path", splitComma: true})
+}
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// Тестирование заполнения структуры параметрами строки запроса.
+func TestDecodeQuery(t *testing.T) {
+	var input struct {
+		Search string   `query:"q"`
+		Page   int      `query:"page" default:"1"`
+		Sort   []string `query:"sort"`
+		Status []string `query:"status"`
+		Limit  *int     `query:"limit"`
+	}
+
+	r := httptest.NewRequest("GET", "/users?q=alice&sort=name,-created_at&status=active&status=blocked&unknown=1", nil)
+	err := DecodeQuery(r, &input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if input.Search != "alice" || input.Page != 1 || input.Limit != nil {
+		t.Errorf("unexpected result %+v", input)
+	}
+	if !reflect.DeepEqual(input.Sort, []string{"name", "-created_at"}) || !reflect.DeepEqual(input.Status, []string{"active", "blocked"}) {
+		t.Errorf("unexpected slices %v, %v", input.Sort, input.Status)
+	}
+
+	// Ошибки преобразования возвращаются по именам параметров.
+	r = httptest.NewRequest("GET", "/users?page=first&limit=-", nil)
+	err = DecodeQuery(r, &input)
+
+	var fieldErrors FieldErrors
+	if !errors.As(err, &fieldErrors) || fieldErrors["page"] != "must be an integer" || fieldErrors["limit"] != "must be an integer" {
+		t.Errorf("expected errors for page and limit, got %v", err)
+	}
+}
+
+// Тестирование заполнения структуры переменными пути.
+func TestDecodePath(t *testing.T) {
+	var input struct {
+		ID   int64  `path:"id"`
+		Slug string `path:"slug"`
+	}
+
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/posts/42/hello", nil), map[string]string{"id": "42", "slug": "hello,world"})
+	err := DecodePath(r, &input)
+	if err != nil || input.ID != 42 || input.Slug != "hello,world" {
+		t.Errorf("unexpected result %+v, %v", input, err)
+	}
+
+	r = mux.SetURLVars(httptest.NewRequest("GET", "/posts/abc", nil), map[string]string{"id": "abc"})
+	err = DecodePath(r, &input)
+
+	var fieldErrors FieldErrors
+	if !errors.As(err, &fieldErrors) || fieldErrors["id"] != "must be an integer" {
+		t.Errorf("expected error for id, got %v", err)
+	}
+}
@@ -0,0 +1,525 @@
+//Этот код предоставляет функции для обработки PATCH-запросов в форматах JSON Merge Patch (RFC 7396)
+//и JSON Patch (RFC 6902): патч применяется к текущему представлению ресурса и результат декодируется в структуру.
+
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Типы содержимого для PATCH-запросов.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// ErrPatchTestFailed возвращается (в составе *PatchError), если операция test не прошла проверку.
+var ErrPatchTestFailed = errors.New("test operation failed")
+
+// PatchError описывает операцию JSON Patch, которую не удалось применить к ресурсу.
+// Обработчики должны отвечать на эту ошибку статусом 422 Unprocessable Entity.
+type PatchError struct {
+	Index int    // Порядковый номер операции в документе патча (начиная с 0).
+	Op    string // Тип операции, например "replace".
+	Path  string // JSON-указатель, к которому относится ошибка.
+	Err   error  // Причина ошибки.
+}
+
+func (e *PatchError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("patch cannot be applied at %q: %s", e.Path, e.Err)
+	}
+	return fmt.Sprintf("patch operation %d (%s %q) cannot be applied: %s", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// patchOperation - операция документа JSON Patch. Поля value и from хранятся как json.RawMessage,
+// чтобы отличать отсутствующее значение от null.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// DecodePatch декодирует тело PATCH-запроса в формате JSON Merge Patch или JSON Patch (по заголовку Content-Type),
+// применяет его к текущему представлению ресурса current и декодирует результат в dst.
+// Ошибки в самом документе патча возвращаются как обычные ошибки декодирования (400 Bad Request),
+// ошибки применения операций, несовпадение типов и члены, которых нет в dst, - как *PatchError (422 Unprocessable Entity),
+// неподдерживаемый тип содержимого - как *UnsupportedMediaTypeError (415 Unsupported Media Type).
+func DecodePatch(w http.ResponseWriter, r *http.Request, current interface{}, dst interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return &UnsupportedMediaTypeError{MediaType: r.Header.Get("Content-Type")}
+	}
+
+	// Получение текущего представления ресурса в виде JSON-документа.
+	doc, err := toDocument(current)
+	if err != nil {
+		return err
+	}
+
+	switch mediaType {
+	case MergePatchContentType:
+		var patch interface{}
+		err = decodeJSONDocument(w, r, &patch)
+		if err != nil {
+			return err
+		}
+		doc = MergePatch(doc, patch)
+
+	case JSONPatchContentType:
+		// Члены операций, не определенные в RFC 6902, игнорируются (раздел 4 RFC 6902).
+		var operations []patchOperation
+		err = decodeJSON(w, r, &operations, false)
+		if err != nil {
+			return err
+		}
+		doc, err = applyJSONPatch(doc, operations)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return &UnsupportedMediaTypeError{MediaType: mediaType}
+	}
+
+	return fromDocument(doc, dst)
+}
+
+// MergePatch применяет документ JSON Merge Patch (RFC 7396) к документу target и возвращает результат.
+// Значение null в патче удаляет поле, объекты объединяются рекурсивно, остальные значения заменяются.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = MergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// applyJSONPatch последовательно применяет операции JSON Patch (RFC 6902) к документу doc.
+// Если какая-либо операция не может быть применена, весь патч отклоняется.
+func applyJSONPatch(doc interface{}, operations []patchOperation) (interface{}, error) {
+	for i, operation := range operations {
+		if operation.Path == nil {
+			return nil, fmt.Errorf("body contains invalid JSON Patch operation %d: missing \"path\"", i)
+		}
+
+		path, err := parsePointer(*operation.Path)
+		if err != nil {
+			return nil, fmt.Errorf("body contains invalid JSON Patch operation %d: %s", i, err)
+		}
+
+		var value interface{}
+		var from []string
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				return nil, fmt.Errorf("body contains invalid JSON Patch operation %d: missing \"value\"", i)
+			}
+			value, err = toDocument(operation.Value)
+			if err != nil {
+				return nil, err
+			}
+
+		case "move", "copy":
+			if operation.From == nil {
+				return nil, fmt.Errorf("body contains invalid JSON Patch operation %d: missing \"from\"", i)
+			}
+			from, err = parsePointer(*operation.From)
+			if err != nil {
+				return nil, fmt.Errorf("body contains invalid JSON Patch operation %d: %s", i, err)
+			}
+
+		case "remove":
+
+		default:
+			return nil, fmt.Errorf("body contains invalid JSON Patch operation %d: unknown op %q", i, operation.Op)
+		}
+
+		doc, err = applyOperation(doc, operation.Op, path, from, value)
+		if err != nil {
+			return nil, &PatchError{Index: i, Op: operation.Op, Path: *operation.Path, Err: err}
+		}
+	}
+
+	return doc, nil
+}
+
+// applyOperation применяет одну операцию JSON Patch к документу. Для операций move и copy
+// from содержит разобранный указатель источника.
+func applyOperation(doc interface{}, op string, path, from []string, value interface{}) (interface{}, error) {
+	switch op {
+	case "add":
+		return addValue(doc, path, value)
+
+	case "remove":
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+
+	case "replace":
+		_, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		doc, _, err = removeValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+
+	case "move", "copy":
+		if op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("a value cannot be moved into one of its children")
+			}
+			doc, value, err := removeValue(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			return addValue(doc, path, value)
+		}
+
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		copied, err := toDocument(value)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, copied)
+
+	default:
+		// Операция test.
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(actual, value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	}
+}
+
+// parsePointer разбирает JSON-указатель (RFC 6901) на отдельные токены.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// getValue возвращает значение документа по пути path.
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	current := doc
+	for _, token := range path {
+		switch container := current.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			current = value
+
+		case []interface{}:
+			i, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = container[i]
+
+		default:
+			return nil, fmt.Errorf("path segment %q does not refer to an object or array", token)
+		}
+	}
+
+	return current, nil
+}
+
+// setDocumentValue заменяет существующее значение документа по пути path и возвращает обновленный документ.
+func setDocumentValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := getValue(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	token := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = value
+	case []interface{}:
+		i, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = value
+	default:
+		return nil, fmt.Errorf("path segment %q does not refer to an object or array", token)
+	}
+
+	return doc, nil
+}
+
+// addValue добавляет значение по пути path: создает или заменяет член объекта, вставляет элемент массива
+// (токен "-" добавляет элемент в конец) и возвращает обновленный документ.
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parentPath, token := path[:len(path)-1], path[len(path)-1]
+	parent, err := getValue(doc, parentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = value
+		return doc, nil
+
+	case []interface{}:
+		i := len(container)
+		if token != "-" {
+			i, err = arrayIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		updated := make([]interface{}, 0, len(container)+1)
+		updated = append(updated, container[:i]...)
+		updated = append(updated, value)
+		updated = append(updated, container[i:]...)
+		return setDocumentValue(doc, parentPath, updated)
+
+	default:
+		return nil, fmt.Errorf("path segment %q does not refer to an object or array", token)
+	}
+}
+
+// removeValue удаляет значение по пути path и возвращает обновленный документ и удаленное значение.
+func removeValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+
+	parentPath, token := path[:len(path)-1], path[len(path)-1]
+	parent, err := getValue(doc, parentPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		value, ok := container[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q does not exist", token)
+		}
+		delete(container, token)
+		return doc, value, nil
+
+	case []interface{}:
+		i, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value := container[i]
+		updated := make([]interface{}, 0, len(container)-1)
+		updated = append(updated, container[:i]...)
+		updated = append(updated, container[i+1:]...)
+		doc, err = setDocumentValue(doc, parentPath, updated)
+		return doc, value, err
+
+	default:
+		return nil, nil, fmt.Errorf("path segment %q does not refer to an object or array", token)
+	}
+}
+
+// arrayIndex разбирает индекс элемента массива и проверяет, что он не превышает max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.HasPrefix(token, "+") {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d is out of range", i)
+	}
+	return i, nil
+}
+
+// isPrefix возвращает true, если путь prefix является началом пути path.
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual сравнивает два JSON-значения; числа сравниваются по значению, а не по записи.
+func jsonEqual(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errX := x.Float64()
+		fy, errY := y.Float64()
+		return errX == nil && errY == nil && fx == fy
+
+	default:
+		return a == b
+	}
+}
+
+// toDocument преобразует значение в JSON-документ из map[string]interface{}, []interface{} и скалярных значений.
+// Числа сохраняются как json.Number, чтобы не терять точность.
+func toDocument(value interface{}) (interface{}, error) {
+	data, ok := value.(json.RawMessage)
+	if !ok {
+		var err error
+		data, err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	err := dec.Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fromDocument декодирует JSON-документ в структуру dst. Несовпадение типов и неизвестные члены означают,
+// что патч записал в поле значение неверного типа или добавил член, которого нет в dst,
+// и возвращаются как *PatchError.
+func fromDocument(doc interface{}, dst interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(dst)
+	if err != nil {
+		var unmarshalTypeError *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &unmarshalTypeError):
+			path := "/" + strings.ReplaceAll(unmarshalTypeError.Field, ".", "/")
+			return &PatchError{Index: -1, Path: path, Err: fmt.Errorf("must be of type %s", unmarshalTypeError.Type)}
+
+		// Для неизвестного члена encoding/json сообщает только его имя, но не путь к нему.
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			name, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+			return &PatchError{Index: -1, Path: "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name), Err: errors.New("unknown member")}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// decodeJSONDocument декодирует JSON-тело запроса в interface{} с сохранением чисел как json.Number.
+func decodeJSONDocument(w http.ResponseWriter, r *http.Request, dst *interface{}) error {
+	maxBytes := limitBody(w, r)
+
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+
+	err := dec.Decode(dst)
+	if err != nil {
+		return jsonError(err, maxBytes)
+	}
+
+	err = dec.Decode(&struct{}{})
+	if !errors.Is(err, io.EOF) {
+		if err != nil && isTooLarge(err) {
+			return errTooLarge(maxBytes)
+		}
+		return errors.New("body must only contain a single JSON value")
+	}
+
+	return nil
+}
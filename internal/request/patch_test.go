@@ -0,0 +1,185 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applyPatch применяет патч body с типом содержимого contentType к документу current
+// и возвращает результат, декодированный в interface{}.
+func applyPatch(contentType, current, body string) (interface{}, error) {
+	r := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+
+	var dst interface{}
+	err := DecodePatch(httptest.NewRecorder(), r, json.RawMessage(current), &dst)
+	return dst, err
+}
+
+// mustUnmarshal декодирует JSON-документ или завершает тест при ошибке.
+func mustUnmarshal(t *testing.T, data string) interface{} {
+	t.Helper()
+
+	var v interface{}
+	err := json.Unmarshal([]byte(data), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// Тестирование JSON Patch на примерах из приложения A RFC 6902.
+func TestJSONPatchRFC6902(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string // Пустая строка - ожидается ошибка.
+		invalid bool   // Ошибка в документе патча (400), а не в применении операции (422).
+	}{
+		{"A.1 добавление члена объекта", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz": "qux", "foo": "bar"}`, false},
+		{"A.2 добавление элемента массива", `{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux", "baz"]}`, false},
+		{"A.3 удаление члена объекта", `{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`, false},
+		{"A.4 удаление элемента массива", `{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`, false},
+		{"A.5 замена значения", `{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`, false},
+		{
+			"A.6 перемещение значения",
+			`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`, false,
+		},
+		{"A.7 перемещение элемента массива", `{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo": ["all", "cows", "eat", "grass"]}`, false},
+		{
+			"A.8 успешная проверка значения",
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`, false,
+		},
+		{"A.9 неуспешная проверка значения", `{"baz": "qux"}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, "", false},
+		{"A.10 добавление вложенного члена", `{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"foo": "bar", "child": {"grandchild": {}}}`, false},
+		{"A.11 игнорирование неизвестных членов", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, `{"foo": "bar", "baz": "qux"}`, false},
+		{"A.12 добавление в несуществующий объект", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, "", false},
+		{"A.14 экранирование ~", `{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}]`, `{"/": 9, "~1": 10}`, false},
+		{"A.15 сравнение строк и чисел", `{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": "10"}]`, "", false},
+		{"A.16 добавление массива", `{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo": ["bar", ["abc", "def"]]}`, false},
+
+		// Некорректные документы патча.
+		{"неизвестная операция", `{"foo": "bar"}`, `[{"op": "merge", "path": "/foo"}]`, "", true},
+		{"некорректный path", `{"foo": "bar"}`, `[{"op": "remove", "path": "foo"}]`, "", true},
+		{"некорректный from", `{"foo": "bar"}`, `[{"op": "copy", "from": "foo", "path": "/baz"}]`, "", true},
+		{"отсутствующий from", `{"foo": "bar"}`, `[{"op": "move", "path": "/baz"}]`, "", true},
+		{"отсутствующее value", `{"foo": "bar"}`, `[{"op": "add", "path": "/baz"}]`, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := applyPatch(JSONPatchContentType, tt.doc, tt.patch)
+
+		if tt.want == "" {
+			var patchError *PatchError
+			if err == nil || errors.As(err, &patchError) == tt.invalid {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if want := mustUnmarshal(t, tt.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, want, got)
+		}
+	}
+}
+
+// Тестирование JSON Merge Patch на примерах из приложения A RFC 7396.
+func TestMergePatchRFC7396(t *testing.T) {
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+
+	for _, tt := range tests {
+		got, err := applyPatch(MergePatchContentType, tt.doc, tt.patch)
+		if err != nil {
+			t.Errorf("%s + %s: unexpected error: %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if want := mustUnmarshal(t, tt.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s + %s: expected %v, got %v", tt.doc, tt.patch, want, got)
+		}
+	}
+}
+
+// Тестирование декодирования результата патча в структуру.
+func TestDecodePatchResult(t *testing.T) {
+	type user struct {
+		Name    string `json:"name"`
+		Age     int    `json:"age"`
+		Version int    `json:"version"`
+	}
+	current := user{Name: "alice", Age: 30, Version: 3}
+
+	tests := []struct {
+		contentType string
+		body        string
+		want        user
+		path        string // JSON-указатель ожидаемой ошибки *PatchError.
+	}{
+		{MergePatchContentType, `{"name": "bob"}`, user{Name: "bob", Age: 30, Version: 3}, ""},
+		{JSONPatchContentType, `[{"op": "test", "path": "/version", "value": 3}, {"op": "replace", "path": "/age", "value": 31}]`, user{Name: "alice", Age: 31, Version: 3}, ""},
+		{MergePatchContentType, `{"age": "old"}`, user{}, "/age"},
+		{MergePatchContentType, `{"nickname": "al"}`, user{}, "/nickname"},
+		{JSONPatchContentType, `[{"op": "add", "path": "/a~1b", "value": 1}]`, user{}, "/a~1b"},
+		{JSONPatchContentType, `[{"op": "test", "path": "/version", "value": 2}]`, user{}, "/version"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("PATCH", "/", strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", tt.contentType)
+
+		var dst user
+		err := DecodePatch(httptest.NewRecorder(), r, current, &dst)
+
+		if tt.path != "" {
+			var patchError *PatchError
+			if !errors.As(err, &patchError) || patchError.Path != tt.path {
+				t.Errorf("%s: expected PatchError at %q, got %v", tt.body, tt.path, err)
+			}
+			continue
+		}
+		if err != nil || dst != tt.want {
+			t.Errorf("%s: unexpected result %+v, %v", tt.body, dst, err)
+		}
+	}
+
+	// Неподдерживаемый тип содержимого.
+	r := httptest.NewRequest("PATCH", "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	var dst user
+	var mediaTypeError *UnsupportedMediaTypeError
+	if err := DecodePatch(httptest.NewRecorder(), r, current, &dst); !errors.As(err, &mediaTypeError) {
+		t.Errorf("expected UnsupportedMediaTypeError, got %v", err)
+	}
+}
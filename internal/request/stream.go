@@ -0,0 +1,134 @@
+//Этот код предоставляет функции для потокового декодирования больших JSON-тел HTTP-запроса
+//(NDJSON или JSON-массив), при котором элементы передаются в обработчик по одному без буферизации всего тела.
+
+package request
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// DecodeJSONStream декодирует тело HTTP-запроса, содержащее JSON-массив или последовательность
+// JSON-значений (NDJSON), и вызывает fn для каждого элемента по порядку.
+// Ошибки декодирования содержат порядковый номер элемента (начиная с 0), ошибка из fn возвращается без изменений
+// и прерывает чтение тела.
+func DecodeJSONStream[T any](w http.ResponseWriter, r *http.Request, fn func(item T) error) error {
+	return decodeJSONStream(w, r, fn, false)
+}
+
+// DecodeJSONStreamStrict работает так же, как DecodeJSONStream, но запрещает неизвестные поля в элементах.
+func DecodeJSONStreamStrict[T any](w http.ResponseWriter, r *http.Request, fn func(item T) error) error {
+	return decodeJSONStream(w, r, fn, true)
+}
+
+func decodeJSONStream[T any](w http.ResponseWriter, r *http.Request, fn func(item T) error, disallowUnknownFields bool) error {
+	maxBytes := limitBody(w, r)
+
+	// Определяем формат тела по первому значимому символу.
+	br := bufio.NewReader(r.Body)
+	first, err := peekNonSpace(br)
+	if err != nil {
+		return jsonError(err, maxBytes)
+	}
+
+	dec := json.NewDecoder(br)
+	if disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if first != '[' {
+		// Тело в формате NDJSON: значения следуют друг за другом до конца тела.
+		for i := 0; ; i++ {
+			var item T
+			err := dec.Decode(&item)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return itemError(i, jsonError(err, maxBytes))
+			}
+
+			err = fn(item)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Тело в формате JSON-массива: пропускаем открывающую скобку и читаем элементы по одному.
+	_, err = dec.Token()
+	if err != nil {
+		return jsonError(err, maxBytes)
+	}
+
+	i := 0
+	for ; dec.More(); i++ {
+		var item T
+		err := dec.Decode(&item)
+		if err != nil {
+			return itemError(i, jsonError(err, maxBytes))
+		}
+
+		err = fn(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Проверяем закрывающую скобку массива.
+	token, err := dec.Token()
+	switch {
+	case errors.Is(err, io.EOF):
+		return errors.New("body contains badly-formed JSON")
+	case err != nil:
+		return itemError(i, jsonError(err, maxBytes))
+	case token != json.Delim(']'):
+		return fmt.Errorf("body contains badly-formed JSON (at character %d)", dec.InputOffset())
+	}
+
+	// Проверяем, что после массива в теле ничего нет.
+	_, err = dec.Token()
+	if !errors.Is(err, io.EOF) {
+		if err != nil && isTooLarge(err) {
+			return errTooLarge(maxBytes)
+		}
+		return errors.New("body must only contain a single JSON value")
+	}
+
+	return nil
+}
+
+// peekNonSpace пропускает пробельные символы и возвращает первый значимый байт, не извлекая его из буфера.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0], nil
+		}
+
+		_, err = br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// itemError добавляет к сообщению об ошибке порядковый номер элемента потока.
+func itemError(i int, err error) error {
+	message := err.Error()
+	if !strings.HasPrefix(message, "body ") || strings.HasPrefix(message, "body must not be larger") {
+		return err
+	}
+
+	return fmt.Errorf("body item %d %s", i, strings.TrimPrefix(message, "body "))
+}
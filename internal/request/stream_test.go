@@ -0,0 +1,103 @@
+package request
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// streamItem - элемент потока в тестах.
+type streamItem struct {
+	ID int `json:"id"`
+}
+
+// collectStream декодирует поток из тела body и возвращает прочитанные элементы.
+func collectStream(body string, strict bool, maxBytes int64) ([]int, error) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	if maxBytes > 0 {
+		r = WithMaxBytes(r, maxBytes)
+	}
+
+	var ids []int
+	fn := func(item streamItem) error {
+		ids = append(ids, item.ID)
+		return nil
+	}
+
+	if strict {
+		return ids, DecodeJSONStreamStrict(httptest.NewRecorder(), r, fn)
+	}
+	err := DecodeJSONStream(httptest.NewRecorder(), r, fn)
+	return ids, err
+}
+
+// Тестирование потокового декодирования JSON-массива и NDJSON.
+func TestDecodeJSONStream(t *testing.T) {
+	tests := map[string]string{
+		"массив":         ` [{"id": 1}, {"id": 2}, {"id": 3}] `,
+		"NDJSON":         "{\"id\": 1}\n{\"id\": 2}\n\n{\"id\": 3}\n",
+		"NDJSON без \\n": `{"id": 1}{"id": 2}{"id": 3}`,
+	}
+
+	for name, body := range tests {
+		ids, err := collectStream(body, false, 0)
+		if err != nil || !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+			t.Errorf("%s: unexpected result %v, %v", name, ids, err)
+		}
+	}
+
+	// Пустой массив.
+	ids, err := collectStream(`[]`, false, 0)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("empty array: unexpected result %v, %v", ids, err)
+	}
+}
+
+// Тестирование ошибок потокового декодирования.
+func TestDecodeJSONStreamErrors(t *testing.T) {
+	tests := []struct {
+		body   string
+		strict bool
+		want   string
+		ids    []int // Элементы, обработанные до ошибки.
+	}{
+		{``, false, "body must not be empty", nil},
+		{`   `, false, "body must not be empty", nil},
+		{`[{"id": 1}, {"id": "two"}]`, false, `body item 1 contains incorrect JSON type for field "id"`, []int{1}},
+		{"{\"id\": 1}\n{\"id\": 2,}", false, "body item 1 contains badly-formed JSON (at character 20)", []int{1}},
+		{`[{"id": 1}, {"id": 2, "extra": true}]`, true, `body item 1 contains unknown key "extra"`, []int{1}},
+		{`[{"id": 1}`, false, "body item 1 contains badly-formed JSON (at character 10)", []int{1}},
+		{`[{"id": 1}] {"id": 2}`, false, "body must only contain a single JSON value", []int{1}},
+	}
+
+	for _, tt := range tests {
+		ids, err := collectStream(tt.body, tt.strict, 0)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%q: expected %q, got %v", tt.body, tt.want, err)
+		}
+		if !reflect.DeepEqual(ids, tt.ids) {
+			t.Errorf("%q: expected items %v before the error, got %v", tt.body, tt.ids, ids)
+		}
+	}
+
+	// Превышение предела размера тела сообщается без номера элемента.
+	body := "[" + strings.Repeat(`{"id": 1},`, 20) + `{"id": 1}]`
+	_, err := collectStream(body, false, 64)
+	if err == nil || err.Error() != "body must not be larger than 64 bytes" {
+		t.Errorf("expected size limit error, got %v", err)
+	}
+
+	// Ошибка обработчика прерывает чтение и возвращается без изменений.
+	errStop := errors.New("stop")
+	calls := 0
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`[{"id": 1}, {"id": 2}]`))
+	err = DecodeJSONStream(httptest.NewRecorder(), r, func(item streamItem) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Errorf("expected handler error after 1 call, got %v after %d", err, calls)
+	}
+}
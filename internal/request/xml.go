@@ -0,0 +1,188 @@
+//Этот код предоставляет функции для декодирования XML-тела HTTP-запроса в структуру
+//с той же обработкой ошибок, что и для JSON.
+
+package request
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeXML декодирует XML-тело HTTP-запроса в структуру, переданную в параметре dst.
+func DecodeXML(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeXML(w, r, dst, false)
+}
+
+// DecodeXMLStrict выполняет строгую декодировку XML-тела HTTP-запроса.
+// Строгая декодировка запрещает неизвестные элементы верхнего уровня в XML-теле.
+func DecodeXMLStrict(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	return decodeXML(w, r, dst, true)
+}
+
+func decodeXML(w http.ResponseWriter, r *http.Request, dst interface{}, disallowUnknownFields bool) error {
+	maxBytes := limitBody(w, r)
+
+	// Тело читается целиком, так как для проверки неизвестных элементов его нужно разобрать повторно.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if isTooLarge(err) {
+			return errTooLarge(maxBytes)
+		}
+		return err
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(body))
+
+	err = dec.Decode(dst)
+	if err != nil {
+		return xmlError(err, maxBytes)
+	}
+
+	// Проверяем, что в XML-теле содержится только один корневой элемент.
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return xmlError(err, maxBytes)
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return errors.New("body must only contain a single XML value")
+			}
+		case xml.Comment, xml.ProcInst:
+		default:
+			return errors.New("body must only contain a single XML value")
+		}
+	}
+
+	if disallowUnknownFields {
+		return checkUnknownXMLElements(body, dst, maxBytes)
+	}
+
+	return nil
+}
+
+// xmlError преобразует ошибку декодирования XML в понятное клиенту сообщение.
+func xmlError(err error, maxBytes int64) error {
+	var syntaxError *xml.SyntaxError
+	var numError *strconv.NumError
+	var unmarshalError xml.UnmarshalError
+
+	switch {
+	case errors.As(err, &syntaxError):
+		if errors.Is(err, io.ErrUnexpectedEOF) || strings.Contains(syntaxError.Msg, "unexpected EOF") {
+			return errors.New("body contains badly-formed XML")
+		}
+		return fmt.Errorf("body contains badly-formed XML (at line %d)", syntaxError.Line)
+
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("body contains badly-formed XML")
+
+	case errors.As(err, &numError):
+		return fmt.Errorf("body contains incorrect XML value %q", numError.Num)
+
+	case errors.As(err, &unmarshalError):
+		return fmt.Errorf("body contains incorrect XML: %s", strings.TrimPrefix(string(unmarshalError), "xml: "))
+
+	case errors.Is(err, io.EOF):
+		return errors.New("body must not be empty")
+
+	case isTooLarge(err):
+		return errTooLarge(maxBytes)
+
+	default:
+		return err
+	}
+}
+
+// checkUnknownXMLElements проверяет, что все дочерние элементы корневого элемента соответствуют полям структуры dst.
+func checkUnknownXMLElements(body []byte, dst interface{}, maxBytes int64) error {
+	known, anyElement := xmlFieldNames(reflect.TypeOf(dst))
+	if anyElement {
+		return nil
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return xmlError(err, maxBytes)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 && !known[t.Name.Local] {
+				return fmt.Errorf("body contains unknown key %q", t.Name.Local)
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// xmlFieldNames возвращает имена элементов, в которые могут быть декодированы поля структуры.
+// Второе значение равно true, если структура принимает произвольные элементы (",any" или ",innerxml").
+func xmlFieldNames(t reflect.Type) (map[string]bool, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, true
+	}
+
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Name == "XMLName" {
+			continue
+		}
+
+		tag := field.Tag.Get("xml")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		switch {
+		case strings.Contains(opts, "any") || strings.Contains(opts, "innerxml"):
+			return nil, true
+		case strings.Contains(opts, "attr") || strings.Contains(opts, "chardata") || strings.Contains(opts, "comment"):
+			continue
+		}
+
+		// Для вложенных путей вида "a>b" учитывается только первый элемент.
+		name, _, _ = strings.Cut(name, ">")
+		if name == "" {
+			if field.Anonymous {
+				embedded, anyElement := xmlFieldNames(field.Type)
+				if anyElement {
+					return nil, true
+				}
+				for name := range embedded {
+					names[name] = true
+				}
+				continue
+			}
+			name = field.Name
+		}
+		names[name] = true
+	}
+
+	return names, false
+}
@@ -0,0 +1,333 @@
+//Пакет schema предоставляет загрузку JSON Schema (Draft 2020-12) и проверку декодированных тел запросов
+//с преобразованием нарушений в ошибки validator.Validator.
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"apiapp/internal/validator"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Schema представляет скомпилированную JSON Schema.
+type Schema struct {
+	name      string
+	schema    *jsonschema.Schema
+	locations map[string]*jsonschema.Schema // Все подсхемы реестра по абсолютному расположению.
+}
+
+// Registry содержит скомпилированные схемы, доступные по имени файла без расширения .json.
+type Registry struct {
+	schemas map[string]*Schema
+}
+
+// Load компилирует все файлы *.json из файловой системы fsys (например, embed.FS или os.DirFS).
+// Схемы могут ссылаться друг на друга через $ref с относительными путями.
+// Если в схеме не указан $schema, используется Draft 2020-12.
+func Load(fsys fs.FS) (*Registry, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+
+	var names []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != ".json" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		err = compiler.AddResource(resourceURL(name), bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{schemas: make(map[string]*Schema, len(names))}
+	locations := map[string]*jsonschema.Schema{}
+	for _, name := range names {
+		compiled, err := compiler.Compile(resourceURL(name))
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+		indexLocations(compiled, locations)
+
+		key := strings.TrimSuffix(name, ".json")
+		registry.schemas[key] = &Schema{name: key, schema: compiled, locations: locations}
+	}
+
+	return registry, nil
+}
+
+// Get возвращает схему по имени, например "users/create" для файла users/create.json.
+func (r *Registry) Get(name string) (*Schema, bool) {
+	s, ok := r.schemas[name]
+	return s, ok
+}
+
+// MustGet возвращает схему по имени и вызывает панику, если схема не найдена.
+// Используется при настройке маршрутов, когда отсутствие схемы - ошибка программиста.
+func (r *Registry) MustGet(name string) *Schema {
+	s, ok := r.Get(name)
+	if !ok {
+		panic(fmt.Sprintf("schema: unknown schema %q", name))
+	}
+	return s
+}
+
+// Names возвращает отсортированный список имен загруженных схем.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name возвращает имя схемы.
+func (s *Schema) Name() string {
+	return s.name
+}
+
+// Validate проверяет декодированный JSON-документ doc (результат json.Unmarshal в interface{})
+// и добавляет каждое нарушение схемы в v как ошибку поля с ключом в виде JSON-указателя, например /items/2/name.
+// Нарушения, относящиеся ко всему документу, добавляются как общие ошибки.
+func (s *Schema) Validate(doc interface{}, v *validator.Validator) {
+	err := s.schema.Validate(doc)
+	if err == nil {
+		return
+	}
+
+	var validationError *jsonschema.ValidationError
+	if !errors.As(err, &validationError) {
+		v.AddError(err.Error())
+		return
+	}
+
+	s.addViolations(validationError, doc, v)
+}
+
+// ValidateJSON проверяет JSON-документ в виде байтов. Ошибка возвращается, только если data не является корректным JSON.
+func (s *Schema) ValidateJSON(data []byte, v *validator.Validator) error {
+	// Числа декодируются как json.Number, чтобы не терять точность при проверке.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	err := dec.Decode(&doc)
+	if err != nil {
+		return err
+	}
+
+	s.Validate(doc, v)
+	return nil
+}
+
+// ValidateValue проверяет произвольное значение (например, структуру после request.DecodeJSON),
+// предварительно преобразуя его в JSON. Учтите, что пропущенные поля структуры кодируются нулевыми значениями.
+func (s *Schema) ValidateValue(value interface{}, v *validator.Validator) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.ValidateJSON(data, v)
+}
+
+// addViolations рекурсивно обходит дерево ошибок и добавляет в v только конечные нарушения.
+func (s *Schema) addViolations(err *jsonschema.ValidationError, doc interface{}, v *validator.Validator) {
+	if len(err.Causes) > 0 {
+		for _, cause := range err.Causes {
+			s.addViolations(cause, doc, v)
+		}
+		return
+	}
+
+	keyword := path.Base(err.KeywordLocation)
+	instance := unescapeLocation(err.InstanceLocation)
+
+	// Для отсутствующих обязательных и запрещенных дополнительных свойств ошибка относится к самим свойствам,
+	// а не к родительскому объекту. Свойства определяются по объявлениям схемы и проверяемому объекту.
+	if keyword == "required" || keyword == "additionalProperties" {
+		properties := s.offendingProperties(err, keyword, resolvePointer(doc, instance))
+		for _, property := range properties {
+			pointer := instance + "/" + escapePointer(property)
+			if keyword == "required" {
+				v.AddFieldDetail(pointer, validator.FieldError{Code: "required", Message: "must be provided"})
+			} else {
+				v.AddFieldDetail(pointer, validator.FieldError{Code: "not_allowed", Message: "is not allowed"})
+			}
+		}
+		if len(properties) > 0 {
+			return
+		}
+	}
+
+	if instance == "" {
+		v.AddError(err.Message)
+		return
+	}
+
+	v.AddFieldDetail(instance, validator.FieldError{Code: keyword, Message: err.Message})
+}
+
+// offendingProperties возвращает отсортированные имена отсутствующих обязательных (keyword == "required")
+// или недопустимых дополнительных (keyword == "additionalProperties") свойств объекта value, к которому относится ошибка.
+func (s *Schema) offendingProperties(err *jsonschema.ValidationError, keyword string, value interface{}) []string {
+	sub, ok := s.locations[strings.TrimSuffix(err.AbsoluteKeywordLocation, "/"+keyword)]
+	if !ok {
+		return nil
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var properties []string
+	if keyword == "required" {
+		for _, property := range sub.Required {
+			if _, exists := object[property]; !exists {
+				properties = append(properties, property)
+			}
+		}
+		return properties
+	}
+
+	for property := range object {
+		if !declaresProperty(sub, property) {
+			properties = append(properties, property)
+		}
+	}
+	sort.Strings(properties)
+	return properties
+}
+
+// declaresProperty проверяет, описано ли свойство в properties или patternProperties схемы.
+func declaresProperty(sub *jsonschema.Schema, property string) bool {
+	if _, ok := sub.Properties[property]; ok {
+		return true
+	}
+	for pattern := range sub.PatternProperties {
+		if pattern.MatchString(property) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePointer возвращает значение документа doc по JSON-указателю (RFC 6901) или nil, если значения нет.
+func resolvePointer(doc interface{}, pointer string) interface{} {
+	if pointer == "" {
+		return doc
+	}
+
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			doc = node[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			doc = node[i]
+		default:
+			return nil
+		}
+	}
+	return doc
+}
+
+// unescapeLocation преобразует расположение значения из ошибки библиотеки, где имена свойств
+// дополнительно закодированы для URI (например, "a%20b"), в обычный JSON-указатель.
+func unescapeLocation(location string) string {
+	tokens := strings.Split(location, "/")
+	for i, token := range tokens {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			tokens[i] = unescaped
+		}
+	}
+	return strings.Join(tokens, "/")
+}
+
+// indexLocations добавляет схему и все ее подсхемы (включая схемы по $ref) в index по абсолютному расположению.
+func indexLocations(s *jsonschema.Schema, index map[string]*jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	if _, seen := index[s.Location]; seen {
+		return
+	}
+	index[s.Location] = s
+
+	children := []*jsonschema.Schema{
+		s.Ref, s.RecursiveRef, s.DynamicRef, s.Not, s.If, s.Then, s.Else,
+		s.PropertyNames, s.UnevaluatedProperties, s.Items2020, s.Contains, s.UnevaluatedItems, s.ContentSchema,
+	}
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	children = append(children, s.PrefixItems...)
+	for _, child := range s.Properties {
+		children = append(children, child)
+	}
+	for _, child := range s.PatternProperties {
+		children = append(children, child)
+	}
+	for _, child := range s.DependentSchemas {
+		children = append(children, child)
+	}
+	for _, child := range s.Dependencies {
+		if child, ok := child.(*jsonschema.Schema); ok {
+			children = append(children, child)
+		}
+	}
+	for _, child := range []interface{}{s.AdditionalProperties, s.AdditionalItems, s.Items} {
+		switch child := child.(type) {
+		case *jsonschema.Schema:
+			children = append(children, child)
+		case []*jsonschema.Schema:
+			children = append(children, child...)
+		}
+	}
+
+	for _, child := range children {
+		indexLocations(child, index)
+	}
+}
+
+// escapePointer экранирует имя свойства для использования в JSON-указателе (RFC 6901).
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// resourceURL возвращает URL, под которым схема регистрируется в компиляторе.
+func resourceURL(name string) string {
+	return "file:///schemas/" + name
+}
@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"apiapp/internal/validator"
+)
+
+// testSchemas - схемы, загружаемые в тестах.
+var testSchemas = fstest.MapFS{
+	"common/address.json": {Data: []byte(`{
+		"type": "object",
+		"required": ["city", "zip code"],
+		"properties": {
+			"city": {"type": "string", "minLength": 1},
+			"zip code": {"type": "string"}
+		}
+	}`)},
+	"users/create.json": {Data: []byte(`{
+		"type": "object",
+		"required": ["name", "a, 'b'", "address"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 3},
+			"a, 'b'": {"type": "string"},
+			"id": {"type": "integer", "maximum": 9007199254740992},
+			"address": {"$ref": "../common/address.json"},
+			"tags": {"type": "array", "items": {"type": "object", "additionalProperties": false, "properties": {"key": {"type": "string"}}}}
+		},
+		"patternProperties": {"^x-": {"type": "string"}}
+	}`)},
+	"README.md": {Data: []byte("not a schema")},
+}
+
+// Тестирование загрузки схем и поиска по имени.
+func TestLoad(t *testing.T) {
+	registry, err := Load(testSchemas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if names := registry.Names(); !reflect.DeepEqual(names, []string{"common/address", "users/create"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+	if s, ok := registry.Get("users/create"); !ok || s.Name() != "users/create" {
+		t.Errorf("expected schema users/create")
+	}
+	if _, ok := registry.Get("users/update"); ok {
+		t.Errorf("unexpected schema users/update")
+	}
+
+	// Некорректная схема - ошибка загрузки с именем файла.
+	_, err = Load(fstest.MapFS{"broken.json": {Data: []byte(`{"type": 1}`)}})
+	if err == nil {
+		t.Errorf("expected error for an invalid schema")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for an unknown schema")
+		}
+	}()
+	registry.MustGet("users/update")
+}
+
+// Тестирование преобразования нарушений схемы в ошибки полей.
+func TestValidateJSON(t *testing.T) {
+	registry, err := Load(testSchemas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := registry.MustGet("users/create")
+
+	tests := []struct {
+		name string
+		body string
+		want map[string][]string // Коды ошибок по JSON-указателям.
+	}{
+		{
+			name: "корректный документ",
+			body: `{"name": "alice", "a, 'b'": "x", "address": {"city": "Berlin", "zip code": "10115"}, "x-trace": "1", "tags": [{"key": "a"}]}`,
+			want: map[string][]string{},
+		},
+		{
+			name: "отсутствующие свойства, в том числе с запятыми и кавычками в имени",
+			body: `{"address": {"city": "Berlin"}}`,
+			want: map[string][]string{
+				"/name":             {"required"},
+				"/a, 'b'":           {"required"},
+				"/address/zip code": {"required"},
+			},
+		},
+		{
+			name: "дополнительные свойства",
+			body: `{"name": "alice", "a, 'b'": "x", "address": {"city": "Berlin", "zip code": "10115"}, "extra/key": 1, "tags": [{"key": "a"}, {"value": "b"}]}`,
+			want: map[string][]string{
+				"/extra~1key":   {"not_allowed"},
+				"/tags/1/value": {"not_allowed"},
+			},
+		},
+		{
+			name: "ограничения значений",
+			body: `{"name": "al", "a, 'b'": 1, "address": {"city": "", "zip code": "10115"}, "id": 9007199254740993}`,
+			want: map[string][]string{
+				"/name":         {"minLength"},
+				"/a, 'b'":       {"type"},
+				"/address/city": {"minLength"},
+				"/id":           {"maximum"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var v validator.Validator
+		err := s.ValidateJSON([]byte(tt.body), &v)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+
+		got := map[string][]string{}
+		for key, details := range v.Details {
+			for _, detail := range details {
+				got[key] = append(got[key], detail.Code)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got: %v\nwant: %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Нарушения, относящиеся ко всему документу, - общие ошибки.
+	var v validator.Validator
+	s.ValidateJSON([]byte(`[]`), &v)
+	if len(v.Errors) != 1 || len(v.FieldErrors) != 0 {
+		t.Errorf("expected a single general error, got %v, %v", v.Errors, v.FieldErrors)
+	}
+
+	// Некорректный JSON - ошибка.
+	if err := s.ValidateJSON([]byte(`{"name":`), &v); err == nil {
+		t.Errorf("expected error for badly-formed JSON")
+	}
+}
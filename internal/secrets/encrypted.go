@@ -0,0 +1,136 @@
+//Код реализует зашифрованный файл секретов: значения хранятся в виде JSON-объекта, зашифрованного
+//AES-256-GCM ключом, полученным из мастер-ключа с помощью scrypt.
+
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Параметры scrypt для получения ключа шифрования из мастер-ключа.
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	keyLength     = 32
+	saltLength    = 16
+	formatVersion = 1
+)
+
+// ErrWrongMasterKey возвращается, если файл секретов не удалось расшифровать мастер-ключом.
+var ErrWrongMasterKey = errors.New("secrets: wrong master key or corrupted secrets file")
+
+// envelope - формат зашифрованного файла секретов.
+type envelope struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// FromEncryptedFile загружает значения из зашифрованного файла секретов, созданного функцией Encrypt.
+func FromEncryptedFile(path, masterKey string) (*Store, error) {
+	if masterKey == "" {
+		return nil, errors.New("secrets: master key is required to read the encrypted secrets file")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: %w", err)
+	}
+
+	values, err := Decrypt(data, masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{name: "secrets-file:" + path, values: values, paths: []string{path}}, nil
+}
+
+// Encrypt шифрует набор значений мастер-ключом и возвращает содержимое файла секретов.
+func Encrypt(values map[string]string, masterKey string) ([]byte, error) {
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(masterKey, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(envelope{
+		Version:    formatVersion,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}, "", "\t")
+}
+
+// Decrypt расшифровывает содержимое файла секретов мастер-ключом.
+func Decrypt(data []byte, masterKey string) (map[string]string, error) {
+	var env envelope
+	err := json.Unmarshal(data, &env)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: invalid secrets file: %w", err)
+	}
+	if env.Version != formatVersion {
+		return nil, fmt.Errorf("secrets: unsupported secrets file version %d", env.Version)
+	}
+
+	aead, err := newAEAD(masterKey, env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongMasterKey
+	}
+
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongMasterKey
+	}
+
+	var values map[string]string
+	err = json.Unmarshal(plaintext, &values)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: invalid secrets file contents: %w", err)
+	}
+
+	return values, nil
+}
+
+// newAEAD создает шифр AES-256-GCM с ключом, полученным из мастер-ключа и соли.
+func newAEAD(masterKey string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(masterKey), salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,109 @@
+//Пакет secrets загружает секретные значения конфигурации из файлов, на которые указывают переменные
+//<KEY>_FILE (секреты Docker и Kubernetes), из каталога с файлами и из зашифрованного файла секретов,
+//а также отслеживает изменения этих файлов для перезагрузки.
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store - набор секретных значений, загруженных из одного источника. Реализует интерфейс
+// источника конфигурации settings.Source.
+type Store struct {
+	name   string
+	values map[string]string
+	paths  []string
+}
+
+// Name возвращает имя источника.
+func (s *Store) Name() string { return s.name }
+
+// Lookup возвращает секретное значение по ключу.
+func (s *Store) Lookup(key string) (string, bool) {
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Paths возвращает пути к файлам и каталогам, из которых загружены значения, для отслеживания изменений.
+func (s *Store) Paths() []string {
+	return s.paths
+}
+
+// FromFileRefs загружает значения для ключей keys из файлов, пути к которым заданы в переменных
+// окружения <KEY>_FILE, например BASIC_AUTH_HASHED_PASSWORD_FILE=/run/secrets/basic_auth_password.
+// Завершающие переводы строки удаляются. Ключи без переменной <KEY>_FILE пропускаются.
+func FromFileRefs(keys []string) (*Store, error) {
+	s := &Store{name: "secret-file", values: make(map[string]string)}
+
+	for _, key := range keys {
+		path, ok := os.LookupEnv(key + "_FILE")
+		if !ok || path == "" {
+			continue
+		}
+
+		value, err := readValue(path)
+		if err != nil {
+			return nil, fmt.Errorf("secrets: %s_FILE: %w", key, err)
+		}
+
+		s.values[key] = value
+		s.paths = append(s.paths, path)
+	}
+
+	return s, nil
+}
+
+// FromDir загружает значения из каталога, в котором каждый файл содержит одно значение, а имя файла
+// является ключом, например /run/secrets/BASIC_AUTH_HASHED_PASSWORD. Имена файлов приводятся к ключам
+// в верхнем регистре с заменой "-" и "." на "_". Скрытые файлы (в том числе служебные ссылки ..data,
+// создаваемые Kubernetes) и подкаталоги пропускаются.
+func FromDir(dir string) (*Store, error) {
+	s := &Store{name: "secrets-dir:" + dir, values: make(map[string]string), paths: []string{dir}}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: %w", err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		// Stat следует по символическим ссылкам, которыми Kubernetes подключает файлы секретов.
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("secrets: %w", err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		value, err := readValue(path)
+		if err != nil {
+			return nil, fmt.Errorf("secrets: %w", err)
+		}
+		s.values[keyFromName(entry.Name())] = value
+	}
+
+	return s, nil
+}
+
+// readValue читает значение из файла, удаляя завершающие переводы строки.
+func readValue(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// keyFromName преобразует имя файла в ключ параметра.
+func keyFromName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
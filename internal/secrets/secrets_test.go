@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Тестирование шифрования и расшифровки файла секретов.
+func TestEncryptDecrypt(t *testing.T) {
+	values := map[string]string{"BASIC_AUTH_HASHED_PASSWORD": "$2a$10$abc", "API_TOKEN": "t0k3n"}
+
+	data, err := Encrypt(values, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Decrypt(data, "master")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["API_TOKEN"] != "t0k3n" || got["BASIC_AUTH_HASHED_PASSWORD"] != "$2a$10$abc" {
+		t.Errorf("unexpected values: %v", got)
+	}
+
+	// Неверный мастер-ключ.
+	_, err = Decrypt(data, "wrong")
+	if !errors.Is(err, ErrWrongMasterKey) {
+		t.Errorf("expected ErrWrongMasterKey, got %v", err)
+	}
+}
+
+// Тестирование загрузки секретов из каталога и из файлов <KEY>_FILE.
+func TestFromDirAndFileRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "basic-auth-username"), "alice\n")
+	writeFile(t, filepath.Join(dir, ".hidden"), "ignored")
+	os.Mkdir(filepath.Join(dir, "..data"), 0o700)
+
+	store, err := FromDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, _ := store.Lookup("BASIC_AUTH_USERNAME"); value != "alice" {
+		t.Errorf("expected alice, got %q", value)
+	}
+	if _, ok := store.Lookup("_HIDDEN"); ok {
+		t.Errorf("expected hidden files to be skipped")
+	}
+
+	path := filepath.Join(dir, "password")
+	writeFile(t, path, "s3cr3t\r\n")
+	t.Setenv("ADMIN_HASHED_PASSWORD_FILE", path)
+
+	store, err = FromFileRefs([]string{"ADMIN_HASHED_PASSWORD", "ADMIN_USERNAME"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, _ := store.Lookup("ADMIN_HASHED_PASSWORD"); value != "s3cr3t" {
+		t.Errorf("expected s3cr3t, got %q", value)
+	}
+	if _, ok := store.Lookup("ADMIN_USERNAME"); ok {
+		t.Errorf("expected ADMIN_USERNAME to be missing")
+	}
+
+	// Изменение файла меняет отпечаток, по которому Watch определяет необходимость перезагрузки.
+	before := fingerprint(store.Paths())
+	writeFile(t, path, "changed")
+	if fingerprint(store.Paths()) == before {
+		t.Errorf("expected fingerprint to change")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,66 @@
+//Код реализует отслеживание изменений файлов секретов путем периодического опроса.
+
+package secrets
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Watch периодически проверяет файлы и каталоги paths и вызывает onChange, если их содержимое изменилось.
+// Опрос используется вместо уведомлений файловой системы, так как он надежно работает с томами Kubernetes,
+// где файлы секретов заменяются атомарной подменой символической ссылки. Возвращает управление при отмене ctx.
+func Watch(ctx context.Context, interval time.Duration, paths []string, onChange func()) {
+	if len(paths) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := fingerprint(paths)
+	for {
+		select {
+		case <-ticker.C:
+			current := fingerprint(paths)
+			if current != last {
+				last = current
+				onChange()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fingerprint вычисляет хеш содержимого файлов. Для каталогов учитываются все файлы верхнего уровня.
+// Ошибки чтения также учитываются, чтобы удаление файла считалось изменением.
+func fingerprint(paths []string) [sha256.Size]byte {
+	h := sha256.New()
+
+	for _, path := range paths {
+		files := []string{path}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			files, _ = filepath.Glob(filepath.Join(path, "*"))
+			sort.Strings(files)
+		}
+
+		for _, file := range files {
+			h.Write([]byte(file))
+			data, err := os.ReadFile(file)
+			if err != nil {
+				h.Write([]byte(err.Error()))
+				continue
+			}
+			h.Write(data)
+		}
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
@@ -0,0 +1,184 @@
+//Код предоставляет Reloader для замены конфигурации во время работы: новая конфигурация загружается
+//и проверяется целиком, сравнивается с текущей, и об изменениях уведомляются подписчики.
+
+package settings
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Change описывает изменение значения параметра конфигурации.
+type Change struct {
+	Key     string
+	Old     string // Прежнее значение; для секретных параметров - Redacted.
+	New     string // Новое значение; для секретных параметров - Redacted.
+	Restart bool   // Изменение требует перезапуска.
+}
+
+// RestartError возвращается Reloader.Reload, если изменены параметры, которые применяются только
+// после перезапуска. В этом случае новая конфигурация не применяется.
+type RestartError struct {
+	Keys []string
+}
+
+// Error перечисляет параметры, изменение которых требует перезапуска.
+func (e *RestartError) Error() string {
+	return fmt.Sprintf("changes to %s require a restart", strings.Join(e.Keys, ", "))
+}
+
+// Diff сравнивает две версии структуры конфигурации и возвращает изменившиеся параметры в порядке
+// объявления полей. oldCfg и newCfg должны быть указателями на структуры одного типа.
+func Diff(oldCfg, newCfg interface{}) []Change {
+	oldValue, newValue := reflect.ValueOf(oldCfg), reflect.ValueOf(newCfg)
+	if oldValue.Kind() != reflect.Pointer || oldValue.Elem().Kind() != reflect.Struct || oldValue.Type() != newValue.Type() {
+		panic("settings: Diff requires two pointers to structs of the same type")
+	}
+
+	oldFields := collect(oldValue.Elem(), "", "", false)
+	newFields := collect(newValue.Elem(), "", "", false)
+
+	var changes []Change
+	for i, f := range oldFields {
+		if reflect.DeepEqual(f.value.Interface(), newFields[i].value.Interface()) {
+			continue
+		}
+
+		change := Change{Key: f.key, Old: formatValue(f.value), New: formatValue(newFields[i].value), Restart: f.restart}
+		if f.secret {
+			change.Old, change.New = Redacted, Redacted
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// Reloader хранит текущую конфигурацию типа T и заменяет ее новой версией при вызове Reload.
+// Методы безопасны для одновременного использования из нескольких горутин.
+type Reloader[T any] struct {
+	mu          sync.Mutex // Упорядочивает перезагрузки и подписку.
+	current     atomic.Pointer[T]
+	load        func() (*T, error)
+	subscribers []func(oldCfg, newCfg *T)
+}
+
+// NewReloader создает Reloader с текущей конфигурацией cfg. Функция load загружает и проверяет
+// новую версию конфигурации, например с помощью Load.
+func NewReloader[T any](cfg *T, load func() (*T, error)) *Reloader[T] {
+	r := &Reloader[T]{load: load}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current возвращает текущую конфигурацию. Возвращаемое значение нельзя изменять.
+func (r *Reloader[T]) Current() *T {
+	return r.current.Load()
+}
+
+// Reload загружает новую версию конфигурации и, если она корректна, делает ее текущей и уведомляет
+// подписчиков. Возвращает список изменений. Если загрузка завершилась ошибкой или изменены параметры
+// с тегом restart:"true", текущая конфигурация не меняется, а в случае *RestartError возвращается
+// также список изменений.
+func (r *Reloader[T]) Reload() ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	newCfg, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	oldCfg := r.current.Load()
+	changes := Diff(oldCfg, newCfg)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	var restartKeys []string
+	for _, change := range changes {
+		if change.Restart {
+			restartKeys = append(restartKeys, change.Key)
+		}
+	}
+	if len(restartKeys) > 0 {
+		return changes, &RestartError{Keys: restartKeys}
+	}
+
+	r.current.Store(newCfg)
+	for _, fn := range r.subscribers {
+		fn(oldCfg, newCfg)
+	}
+
+	return changes, nil
+}
+
+// Subscribe регистрирует функцию fn, которая вызывается после применения новой конфигурации, если
+// значение, выбранное функцией get, изменилось. Функция fn получает новое значение и вызывается
+// синхронно из Reload, поэтому не должна выполняться долго. Например:
+//
+//	settings.Subscribe(reloader, func(cfg *config) slog.Level { return cfg.LogLevel }, logLevel.Set)
+func Subscribe[T, V any](r *Reloader[T], get func(cfg *T) V, fn func(value V)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers = append(r.subscribers, func(oldCfg, newCfg *T) {
+		value := get(newCfg)
+		if !reflect.DeepEqual(get(oldCfg), value) {
+			fn(value)
+		}
+	})
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// formatValue форматирует значение поля в том же виде, в котором оно задается в источниках:
+// срезы - через запятую, map - парами key=value.
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Type() == urlType:
+		u := v.Interface().(url.URL)
+		return u.String()
+	case v.Type().Implements(textMarshalerType):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(items, ",")
+
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, fmt.Sprintf("%v=%s", iter.Key().Interface(), formatValue(iter.Value())))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,382 @@
+//Пакет settings заполняет структуру конфигурации из переменных окружения (и других источников) по тегам env
+//и default, преобразует значения к типам полей и проверяет результат правилами из тегов validate.
+//Все ошибки собираются в один отчет, чтобы их можно было исправить за один запуск.
+
+package settings
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"apiapp/internal/validator"
+)
+
+// Source предоставляет значения параметров конфигурации по ключам, например по именам переменных окружения.
+type Source interface {
+	Name() string
+	Lookup(key string) (string, bool)
+}
+
+// Env - источник значений из переменных окружения процесса.
+type Env struct{}
+
+// Name возвращает имя источника.
+func (Env) Name() string { return "env" }
+
+// Lookup возвращает значение переменной окружения key.
+func (Env) Lookup(key string) (string, bool) { return os.LookupEnv(key) }
+
+// Validatable реализуется структурами конфигурации, которым нужны проверки, не выражаемые тегами validate.
+// Ключами ошибок полей служат пути полей Go, например "Admin.HashedPassword".
+type Validatable interface {
+	Validate(v *validator.Validator)
+}
+
+// Problem описывает ошибку в значении одного параметра конфигурации.
+type Problem struct {
+	Key     string // Ключ параметра, например "HTTP_PORT".
+	Message string
+}
+
+// Error содержит все ошибки, найденные при загрузке конфигурации.
+type Error struct {
+	Problems []Problem
+}
+
+// Error формирует читаемый отчет по всем ошибкам, по одной на строку.
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, p := range e.Problems {
+		b.WriteString("\n  ")
+		if p.Key != "" {
+			b.WriteString(p.Key)
+			b.WriteString(": ")
+		}
+		b.WriteString(p.Message)
+	}
+	return b.String()
+}
+
+// field описывает поле структуры конфигурации, связанное с ключом параметра.
+type field struct {
+	path       string // Путь поля Go, например "Admin.Addr".
+	key        string // Ключ параметра с учетом префиксов, например "ADMIN_ADDR".
+	defaultVal string
+	hasDefault bool
+	secret     bool
+	restart    bool // Изменение значения применяется только после перезапуска.
+	value      reflect.Value
+}
+
+// Load заполняет структуру, на которую указывает dst, значениями из источников sources
+// (по умолчанию - из переменных окружения). Если ключ есть в нескольких источниках,
+// используется значение из последнего. Поддерживаемые теги полей:
+//
+//	env:"HTTP_PORT"         - ключ параметра;
+//	default:"4444"          - значение по умолчанию;
+//	envPrefix:"ADMIN_"      - префикс ключей полей вложенной структуры;
+//	secret:"true"           - значение не выводится в сообщениях об ошибках;
+//	restart:"true"          - значение нельзя изменить без перезапуска (см. Reloader); для вложенной
+//	                          структуры действует на все ее поля;
+//	validate:"required,..." - правила internal/validator.
+//
+// Поддерживаются строки, булевы значения, целые и вещественные числа, time.Duration, url.URL,
+// типы с encoding.TextUnmarshaler, указатели на них, срезы (значения через запятую)
+// и map со строковыми ключами (пары key=value через запятую).
+// Все ошибки преобразования и валидации возвращаются вместе в виде *Error.
+func Load(dst interface{}, sources ...Source) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic("settings: Load requires a pointer to a struct")
+	}
+
+	if len(sources) == 0 {
+		sources = []Source{Env{}}
+	}
+
+	fields := collect(rv.Elem(), "", "", false)
+	report := &Error{}
+	failed := make(map[string]bool)
+
+	// Заполнение полей значениями из источников или значениями по умолчанию.
+	for _, f := range fields {
+		raw, _, found := lookupSource(sources, f.key)
+		if !found {
+			if !f.hasDefault {
+				continue
+			}
+			raw = f.defaultVal
+		}
+
+		err := setValue(f.value, raw)
+		if err != nil {
+			message := err.Error()
+			if !f.secret {
+				message = fmt.Sprintf("%s (got %q)", message, raw)
+			}
+			report.Problems = append(report.Problems, Problem{Key: f.key, Message: message})
+
+			// Поле с ошибкой преобразования сбрасывается, чтобы проверки не видели частично разобранное значение.
+			f.value.Set(reflect.Zero(f.value.Type()))
+			failed[f.key] = true
+		}
+	}
+
+	// Правила validate проверяются для всех полей, но ошибки полей, которые не удалось преобразовать,
+	// не добавляются: для них в отчете уже есть ошибка преобразования.
+	v := validator.ValidateStruct(dst)
+	if custom, ok := dst.(Validatable); ok {
+		custom.Validate(&v)
+	}
+	for _, p := range validationProblems(v, fields) {
+		if !failed[baseKey(p.Key)] {
+			report.Problems = append(report.Problems, p)
+		}
+	}
+
+	if len(report.Problems) > 0 {
+		return report
+	}
+
+	return nil
+}
+
+// collect обходит структуру и возвращает поля с тегом env, в том числе из вложенных структур.
+// Поля вложенных структур наследуют признак restart родительской структуры.
+func collect(rv reflect.Value, path, prefix string, restart bool) []field {
+	var fields []field
+
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fieldPath := sf.Name
+		if path != "" {
+			fieldPath = path + "." + sf.Name
+		}
+
+		key, hasKey := sf.Tag.Lookup("env")
+		if key == "-" {
+			continue
+		}
+		fieldRestart := restart || sf.Tag.Get("restart") == "true"
+
+		// Вложенная структура без собственного ключа: ее поля получают префикс из тега envPrefix.
+		if !hasKey && isNested(sf.Type) {
+			fields = append(fields, collect(rv.Field(i), fieldPath, prefix+sf.Tag.Get("envPrefix"), fieldRestart)...)
+			continue
+		}
+		if !hasKey {
+			continue
+		}
+
+		defaultVal, hasDefault := sf.Tag.Lookup("default")
+		fields = append(fields, field{
+			path:       fieldPath,
+			key:        prefix + key,
+			defaultVal: defaultVal,
+			hasDefault: hasDefault,
+			secret:     sf.Tag.Get("secret") == "true",
+			restart:    fieldRestart,
+			value:      rv.Field(i),
+		})
+	}
+
+	return fields
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isNested возвращает true для структур, которые обходятся как группы параметров,
+// а не заполняются из одного значения.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != urlType && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setValue преобразует строку raw к типу значения v и устанавливает его.
+func setValue(v reflect.Value, raw string) error {
+	// Указатели создаются при наличии значения.
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		err := setValue(elem.Elem(), raw)
+		if err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	// Типы, умеющие разбирать себя из текста, например slog.Level или net.IP.
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+		if err != nil {
+			return fmt.Errorf("must be a valid %s", v.Type().Name())
+		}
+		return nil
+	}
+
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.New("must be a duration such as 30s or 1m30s")
+		}
+		v.SetInt(int64(d))
+		return nil
+
+	case urlType:
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL")
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(raw), 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(raw), v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(n)
+
+	case reflect.Slice:
+		items := splitList(raw)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			err := setValue(slice.Index(i), item)
+			if err != nil {
+				return fmt.Errorf("item %d: %w", i+1, err)
+			}
+		}
+		v.Set(slice)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+
+		m := reflect.MakeMap(v.Type())
+		for _, item := range splitList(raw) {
+			key, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return errors.New("must be a comma-separated list of key=value pairs")
+			}
+
+			elem := reflect.New(v.Type().Elem()).Elem()
+			err := setValue(elem, strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("key %q: %w", strings.TrimSpace(key), err)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// splitList разбивает строку со значениями через запятую, удаляя пробелы и пустые элементы.
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validationProblems преобразует ошибки валидатора в ошибки параметров, заменяя пути полей Go
+// (например "Admin.Addr" или "Listen[1]") ключами параметров ("ADMIN_ADDR", "HTTP_LISTEN[1]").
+func validationProblems(v validator.Validator, fields []field) []Problem {
+	keys := make(map[string]string, len(fields))
+	for _, f := range fields {
+		keys[f.path] = f.key
+	}
+
+	var problems []Problem
+	for _, message := range v.Errors {
+		problems = append(problems, Problem{Message: message})
+	}
+
+	var fieldProblems []Problem
+	for path, message := range v.FieldErrors {
+		fieldProblems = append(fieldProblems, Problem{Key: keyForPath(keys, path), Message: message})
+	}
+
+	sort.Slice(fieldProblems, func(i, j int) bool {
+		return fieldProblems[i].Key < fieldProblems[j].Key
+	})
+
+	return append(problems, fieldProblems...)
+}
+
+// baseKey возвращает ключ параметра без индекса элемента или ключа map, например "HTTP_LISTEN"
+// для "HTTP_LISTEN[1]".
+func baseKey(key string) string {
+	base, _, _ := strings.Cut(key, "[")
+	return base
+}
+
+// keyForPath находит самый длинный префикс пути, для которого известен ключ параметра,
+// и заменяет его ключом, сохраняя остаток пути (индекс элемента или ключ map).
+func keyForPath(keys map[string]string, path string) string {
+	for base := path; base != ""; {
+		if key, ok := keys[base]; ok {
+			return key + path[len(base):]
+		}
+
+		i := strings.LastIndexAny(base, "[.")
+		if i < 0 {
+			break
+		}
+		base = base[:i]
+	}
+
+	return path
+}
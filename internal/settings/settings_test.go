@@ -0,0 +1,280 @@
+package settings
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mapSource - источник значений для тестов.
+type mapSource map[string]string
+
+func (m mapSource) Name() string { return "test" }
+
+func (m mapSource) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+type testConfig struct {
+	Port    int               `env:"PORT" default:"4444" restart:"true" validate:"between=1 65535"`
+	Ratio   float64           `env:"RATIO" default:"0.5"`
+	Timeout time.Duration     `env:"TIMEOUT" default:"5s"`
+	BaseURL url.URL           `env:"BASE_URL" default:"http://localhost"`
+	Level   slog.Level        `env:"LOG_LEVEL" default:"info"`
+	Origins []string          `env:"ORIGINS"`
+	Limits  map[string]int    `env:"LIMITS"`
+	Debug   *bool             `env:"DEBUG"`
+	Labels  map[string]string `env:"LABELS"`
+	DB      struct {
+		DSN      string `env:"DSN" validate:"required"`
+		Password string `env:"PASSWORD" secret:"true"`
+		MaxConns int    `env:"MAX_CONNS" default:"10"`
+	} `envPrefix:"DB_"`
+}
+
+// Тестирование заполнения полей всех поддерживаемых типов.
+func TestLoad(t *testing.T) {
+	var cfg testConfig
+	err := Load(&cfg, mapSource{
+		"PORT":     "8080",
+		"TIMEOUT":  "1m30s",
+		"BASE_URL": "https://api.example.com/v1",
+		"ORIGINS":  "https://a.example, https://b.example",
+		"LIMITS":   "read=100, write=10",
+		"DEBUG":    "true",
+		"DB_DSN":   "postgres://localhost/app",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Port != 8080 || cfg.Ratio != 0.5 || cfg.Timeout != 90*time.Second || cfg.Level != slog.LevelInfo {
+		t.Errorf("unexpected scalar values: %+v", cfg)
+	}
+	if cfg.BaseURL.Host != "api.example.com" {
+		t.Errorf("expected host api.example.com, got %q", cfg.BaseURL.Host)
+	}
+	if !reflect.DeepEqual(cfg.Origins, []string{"https://a.example", "https://b.example"}) {
+		t.Errorf("unexpected origins: %v", cfg.Origins)
+	}
+	if !reflect.DeepEqual(cfg.Limits, map[string]int{"read": 100, "write": 10}) {
+		t.Errorf("unexpected limits: %v", cfg.Limits)
+	}
+	if cfg.Debug == nil || !*cfg.Debug {
+		t.Errorf("expected debug to be set")
+	}
+	if cfg.DB.DSN != "postgres://localhost/app" || cfg.DB.MaxConns != 10 {
+		t.Errorf("unexpected nested values: %+v", cfg.DB)
+	}
+}
+
+// Тестирование приоритета источников: значение из последнего источника переопределяет предыдущие.
+func TestLoadSourcePriority(t *testing.T) {
+	var cfg testConfig
+	err := Load(&cfg, mapSource{"PORT": "1000", "DB_DSN": "a"}, mapSource{"PORT": "2000"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 2000 || cfg.DB.DSN != "a" {
+		t.Errorf("expected PORT=2000 and DB_DSN=a, got %d and %q", cfg.Port, cfg.DB.DSN)
+	}
+}
+
+// Тестирование сбора всех ошибок в один отчет.
+func TestLoadErrors(t *testing.T) {
+	var cfg testConfig
+	err := Load(&cfg, mapSource{
+		"PORT":         "abc",
+		"TIMEOUT":      "5",
+		"LIMITS":       "read",
+		"DB_PASSWORD":  "x",
+		"DB_MAX_CONNS": "many",
+	})
+
+	var report *Error
+	if !errors.As(err, &report) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+
+	keys := make(map[string]string)
+	for _, p := range report.Problems {
+		keys[p.Key] = p.Message
+	}
+	for _, key := range []string{"PORT", "TIMEOUT", "LIMITS", "DB_MAX_CONNS"} {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("expected a problem for %s, got %v", key, report.Problems)
+		}
+	}
+	if !strings.Contains(keys["PORT"], `"abc"`) {
+		t.Errorf("expected the invalid value in the message, got %q", keys["PORT"])
+	}
+
+	// Правила validate проверяются и при ошибках преобразования других полей, а для поля с ошибкой
+	// преобразования ошибка валидации не добавляется.
+	if keys["DB_DSN"] != "must be provided" {
+		t.Errorf("expected a validation problem for DB_DSN, got %v", report.Problems)
+	}
+	if len(report.Problems) != 5 {
+		t.Errorf("expected 5 problems, got %v", report.Problems)
+	}
+
+	// Ошибки валидации используют ключи параметров.
+	err = Load(&cfg, mapSource{"PORT": "70000"})
+	if !errors.As(err, &report) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	message := report.Error()
+	if !strings.Contains(message, "PORT: must be between 1 and 65535") || !strings.Contains(message, "DB_DSN: must be provided") {
+		t.Errorf("unexpected report:\n%s", message)
+	}
+}
+
+// Тестирование сокрытия значений секретных параметров в отчете.
+func TestLoadSecretNotReported(t *testing.T) {
+	type secretConfig struct {
+		Token int `env:"TOKEN" secret:"true"`
+	}
+
+	var cfg secretConfig
+	err := Load(&cfg, mapSource{"TOKEN": "s3cr3t"})
+	if err == nil || strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("expected an error without the secret value, got %v", err)
+	}
+}
+
+// Тестирование чтения файлов конфигурации в поддерживаемых форматах.
+func TestLoadFile(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "port: 8080\nlog_level: debug\norigins: [https://a.example, https://b.example]\ndb:\n  dsn: postgres://db\n",
+		"config.toml": "port = 8080\nlog_level = \"debug\"\norigins = [\"https://a.example\", \"https://b.example\"]\n[db]\ndsn = \"postgres://db\"\n",
+		"config.json": `{"port": 8080, "log_level": "debug", "origins": ["https://a.example", "https://b.example"], "db": {"dsn": "postgres://db"}}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			err := os.WriteFile(path, []byte(content), 0o600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := LoadFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var cfg testConfig
+			err = Load(&cfg, file)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Port != 8080 || cfg.Level != slog.LevelDebug || len(cfg.Origins) != 2 || cfg.DB.DSN != "postgres://db" {
+				t.Errorf("unexpected values: %+v", cfg)
+			}
+		})
+	}
+}
+
+// Тестирование флагов и описания источников действующих значений.
+func TestFlagsAndExplain(t *testing.T) {
+	var cfg testConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := NewFlags(fs, &cfg)
+
+	err := fs.Parse([]string{"-port", "9090", "-db-password", "hunter2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := Explain(&cfg, mapSource{"PORT": "1000", "DB_DSN": "postgres://db"}, flags)
+
+	want := map[string]Value{
+		"PORT":        {Key: "PORT", Value: "9090", Source: "flag"},
+		"DB_DSN":      {Key: "DB_DSN", Value: "postgres://db", Source: "test"},
+		"DB_PASSWORD": {Key: "DB_PASSWORD", Value: Redacted, Source: "flag"},
+		"TIMEOUT":     {Key: "TIMEOUT", Value: "5s", Source: "default"},
+		"ORIGINS":     {Key: "ORIGINS"},
+	}
+	for _, value := range values {
+		if expected, ok := want[value.Key]; ok && value != expected {
+			t.Errorf("expected %+v, got %+v", expected, value)
+		}
+	}
+}
+
+// Тестирование перезагрузки конфигурации с уведомлением подписчиков.
+func TestReloader(t *testing.T) {
+	source := mapSource{"DB_DSN": "postgres://", "DB_PASSWORD": "old", "ORIGINS": "a,b"}
+	load := func() (*testConfig, error) {
+		var cfg testConfig
+		return &cfg, Load(&cfg, source)
+	}
+
+	cfg, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReloader(cfg, load)
+
+	var levels []slog.Level
+	var origins [][]string
+	Subscribe(r, func(cfg *testConfig) slog.Level { return cfg.Level }, func(level slog.Level) { levels = append(levels, level) })
+	Subscribe(r, func(cfg *testConfig) []string { return cfg.Origins }, func(value []string) { origins = append(origins, value) })
+
+	// Изменение уровня логгирования и секрета: уведомляется только подписчик уровня, секрет скрыт в списке изменений.
+	source["LOG_LEVEL"] = "debug"
+	source["DB_PASSWORD"] = "new"
+	changes, err := r.Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Change{
+		{Key: "LOG_LEVEL", Old: "INFO", New: "DEBUG"},
+		{Key: "DB_PASSWORD", Old: Redacted, New: Redacted},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("expected %v, got %v", want, changes)
+	}
+	if !reflect.DeepEqual(levels, []slog.Level{slog.LevelDebug}) || len(origins) != 0 {
+		t.Errorf("unexpected notifications: levels %v, origins %v", levels, origins)
+	}
+	if r.Current().DB.Password != "new" {
+		t.Errorf("expected new configuration to be applied")
+	}
+
+	// Некорректная конфигурация и изменение параметра, требующего перезапуска, не применяются.
+	source["PORT"] = "0"
+	source["ORIGINS"] = "c"
+	_, err = r.Reload()
+	var configError *Error
+	if !errors.As(err, &configError) {
+		t.Errorf("expected *Error, got %v", err)
+	}
+
+	source["PORT"] = "8080"
+	_, err = r.Reload()
+	var restartError *RestartError
+	if !errors.As(err, &restartError) || err.Error() != "changes to PORT require a restart" {
+		t.Errorf("expected *RestartError, got %v", err)
+	}
+	if r.Current().Port != 4444 || len(origins) != 0 {
+		t.Errorf("expected configuration to stay unchanged")
+	}
+
+	// Без изменений подписчики не вызываются.
+	delete(source, "PORT")
+	source["ORIGINS"] = "a,b"
+	changes, err = r.Reload()
+	if err != nil || changes != nil || len(levels) != 1 {
+		t.Errorf("expected no changes, got %v, %v", changes, err)
+	}
+}
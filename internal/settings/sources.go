@@ -0,0 +1,263 @@
+//Код предоставляет источники параметров конфигурации: файлы YAML, TOML и JSON, флаги командной строки,
+//а также функции Fields и Explain для описания полей и действующих значений конфигурации.
+
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted заменяет значения секретных параметров при выводе конфигурации.
+const Redacted = "[REDACTED]"
+
+// Info описывает параметр конфигурации.
+type Info struct {
+	Key        string // Ключ параметра, например "HTTP_PORT".
+	Path       string // Путь поля Go, например "Server.IdleTimeout".
+	Type       string // Тип поля Go.
+	Default    string
+	HasDefault bool
+	Secret     bool
+	Restart    bool // Изменение значения требует перезапуска.
+}
+
+// Fields возвращает описание всех параметров структуры конфигурации, на которую указывает dst.
+func Fields(dst interface{}) []Info {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic("settings: Fields requires a pointer to a struct")
+	}
+
+	var infos []Info
+	for _, f := range collect(rv.Elem(), "", "", false) {
+		infos = append(infos, Info{
+			Key:        f.key,
+			Path:       f.path,
+			Type:       f.value.Type().String(),
+			Default:    f.defaultVal,
+			HasDefault: f.hasDefault,
+			Secret:     f.secret,
+			Restart:    f.restart,
+		})
+	}
+	return infos
+}
+
+// Value описывает действующее значение параметра и его источник.
+type Value struct {
+	Key    string
+	Value  string // Значение в исходном виде; для секретных параметров - Redacted.
+	Source string // Имя источника, "default" для значения по умолчанию или пустая строка, если значение не задано.
+}
+
+// Explain возвращает действующие значения всех параметров после объединения источников sources
+// (по умолчанию - переменных окружения) с указанием источника каждого значения. Значения секретных
+// параметров заменяются на Redacted. Значения не проверяются - для этого используется Load.
+func Explain(dst interface{}, sources ...Source) []Value {
+	if len(sources) == 0 {
+		sources = []Source{Env{}}
+	}
+
+	var values []Value
+	for _, info := range Fields(dst) {
+		value := Value{Key: info.Key}
+
+		switch raw, source, found := lookupSource(sources, info.Key); {
+		case found:
+			value.Value, value.Source = raw, source
+		case info.HasDefault:
+			value.Value, value.Source = info.Default, "default"
+		}
+
+		if info.Secret && value.Value != "" {
+			value.Value = Redacted
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// lookupSource ищет ключ в источниках, начиная с последнего, и возвращает также имя источника.
+func lookupSource(sources []Source, key string) (string, string, bool) {
+	for i := len(sources) - 1; i >= 0; i-- {
+		value, ok := sources[i].Lookup(key)
+		if ok {
+			return value, sources[i].Name(), true
+		}
+	}
+	return "", "", false
+}
+
+// File - источник значений из файла конфигурации.
+type File struct {
+	path   string
+	values map[string]string
+}
+
+// LoadFile читает файл конфигурации в формате YAML (.yaml, .yml), TOML (.toml) или JSON (.json).
+// Вложенные разделы объединяются с ключами через "_" в верхнем регистре, поэтому значение
+// basic_auth.username из файла соответствует ключу BASIC_AUTH_USERNAME. Списки объединяются через запятую.
+func LoadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&doc)
+	default:
+		return nil, fmt.Errorf("settings: unsupported config file format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("settings: parse %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	err = flatten(values, "", doc)
+	if err != nil {
+		return nil, fmt.Errorf("settings: %s: %w", path, err)
+	}
+
+	return &File{path: path, values: values}, nil
+}
+
+// Name возвращает имя источника с путем к файлу.
+func (f *File) Name() string { return "file:" + f.path }
+
+// Lookup возвращает значение параметра key из файла.
+func (f *File) Lookup(key string) (string, bool) {
+	value, ok := f.values[key]
+	return value, ok
+}
+
+// flatten преобразует вложенный документ в плоский набор ключей.
+func flatten(values map[string]string, prefix string, doc map[string]interface{}) error {
+	for name, raw := range doc {
+		key := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		if nested, ok := raw.(map[string]interface{}); ok {
+			err := flatten(values, key, nested)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, err := formatScalar(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		values[key] = value
+	}
+
+	return nil
+}
+
+// formatScalar преобразует значение из документа в строку в формате, который понимает Load.
+func formatScalar(raw interface{}) (string, error) {
+	switch value := raw.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int, int64, uint64, json.Number:
+		return fmt.Sprint(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case time.Time:
+		return value.Format(time.RFC3339Nano), nil
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			s, err := formatScalar(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", raw)
+	}
+}
+
+// Flags - источник значений из флагов командной строки, созданных для каждого параметра конфигурации.
+type Flags struct {
+	values map[string]*flagValue
+}
+
+// flagValue хранит значение флага и признак того, что флаг был указан.
+type flagValue struct {
+	value string
+	set   bool
+}
+
+func (f *flagValue) String() string { return f.value }
+
+func (f *flagValue) Set(value string) error {
+	f.value, f.set = value, true
+	return nil
+}
+
+// NewFlags регистрирует в наборе fs флаг для каждого параметра структуры конфигурации dst.
+// Имя флага образуется из ключа: HTTP_PORT - -http-port. Значения доступны после fs.Parse,
+// и только для флагов, указанных в командной строке.
+func NewFlags(fs *flag.FlagSet, dst interface{}) *Flags {
+	f := &Flags{values: make(map[string]*flagValue)}
+
+	for _, info := range Fields(dst) {
+		value := &flagValue{}
+		f.values[info.Key] = value
+
+		usage := "переопределяет " + info.Key
+		if info.HasDefault && !info.Secret {
+			usage = fmt.Sprintf("%s (по умолчанию %q)", usage, info.Default)
+		}
+		fs.Var(value, FlagName(info.Key), usage)
+	}
+
+	return f
+}
+
+// FlagName возвращает имя флага для ключа параметра.
+func FlagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// Name возвращает имя источника.
+func (f *Flags) Name() string { return "flag" }
+
+// Lookup возвращает значение флага для ключа key, если флаг был указан.
+func (f *Flags) Lookup(key string) (string, bool) {
+	value, ok := f.values[key]
+	if !ok || !value.set {
+		return "", false
+	}
+	return value.value, true
+}
@@ -0,0 +1,133 @@
+//Пакет systemd реализует получение сокетов при активации через systemd (LISTEN_FDS)
+//и отправку уведомлений о состоянии сервиса через протокол sd_notify.
+
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Состояния, передаваемые через Notify.
+const (
+	Ready     = "READY=1"     // Сервис запущен и готов обслуживать запросы.
+	Stopping  = "STOPPING=1"  // Сервис начал завершение работы.
+	Reloading = "RELOADING=1" // Сервис перезагружает конфигурацию.
+	Watchdog  = "WATCHDOG=1"  // Сервис работоспособен (сброс таймера watchdog).
+)
+
+// listenFDsStart - номер первого дескриптора, переданного systemd.
+const listenFDsStart = 3
+
+// Listener - сокет, переданный systemd, вместе с его именем из FileDescriptorName= (или "unknown").
+type Listener struct {
+	Name string
+	net.Listener
+}
+
+// Listeners возвращает слушающие сокеты, переданные процессу при активации через systemd.
+// Если процесс запущен без активации, возвращает пустой срез. Переменные окружения LISTEN_*
+// удаляются, чтобы их не унаследовали дочерние процессы.
+func Listeners() ([]Listener, error) {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	// Сокеты предназначены только процессу с указанным PID.
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make([]Listener, 0, count)
+	for i := 0; i < count; i++ {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(listenFDsStart+i), name)
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("systemd: listener %d (%s): %w", i, name, err)
+		}
+
+		listeners = append(listeners, Listener{Name: name, Listener: ln})
+	}
+
+	return listeners, nil
+}
+
+// Notify отправляет сообщение о состоянии менеджеру сервисов через сокет из NOTIFY_SOCKET.
+// Возвращает false без ошибки, если процесс запущен не под управлением systemd.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	// Имена абстрактных сокетов передаются с префиксом "@".
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// WatchdogInterval возвращает интервал watchdog, заданный systemd через WATCHDOG_USEC.
+// Уведомления Watchdog следует отправлять чаще, как правило, с половиной этого интервала.
+// Возвращает 0, если watchdog не включен для текущего процесса.
+func WatchdogInterval() (time.Duration, error) {
+	value := os.Getenv("WATCHDOG_USEC")
+	if value == "" {
+		return 0, nil
+	}
+
+	// Если задан WATCHDOG_PID, watchdog относится только к процессу с этим PID.
+	if pidValue := os.Getenv("WATCHDOG_PID"); pidValue != "" {
+		pid, err := strconv.Atoi(pidValue)
+		if err != nil {
+			return 0, fmt.Errorf("systemd: invalid WATCHDOG_PID: %w", err)
+		}
+		if pid != os.Getpid() {
+			return 0, nil
+		}
+	}
+
+	usec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("systemd: invalid WATCHDOG_USEC: %w", err)
+	}
+	if usec <= 0 {
+		return 0, errors.New("systemd: WATCHDOG_USEC must be positive")
+	}
+
+	return time.Duration(usec) * time.Microsecond, nil
+}
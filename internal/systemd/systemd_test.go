@@ -0,0 +1,183 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envTestChild - переменная окружения, по которой тестовый бинарный файл, запущенный с сокетами
+// как при активации через systemd, выводит полученные сокеты вместо запуска тестов.
+const envTestChild = "SYSTEMD_TEST_CHILD"
+
+// TestMain выполняет роль активированного процесса, если тестовый бинарный файл запущен TestListeners.
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestChild) != "" {
+		listeners, err := Listeners()
+		if err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		for _, ln := range listeners {
+			fmt.Printf("%s=%s\n", ln.Name, ln.Addr())
+		}
+		fmt.Printf("LISTEN_FDS=%s\n", os.Getenv("LISTEN_FDS"))
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// Тестирование получения сокетов, переданных при активации.
+func TestListeners(t *testing.T) {
+	// Процесс запущен без активации или сокеты предназначены другому процессу.
+	for _, env := range [][2]string{{"", ""}, {"1", "1"}, {strconv.Itoa(os.Getpid()), "many"}, {strconv.Itoa(os.Getpid()), "0"}} {
+		t.Setenv("LISTEN_PID", env[0])
+		t.Setenv("LISTEN_FDS", env[1])
+
+		listeners, err := Listeners()
+		if err != nil || len(listeners) != 0 {
+			t.Errorf("LISTEN_PID=%q LISTEN_FDS=%q: unexpected result %v, %v", env[0], env[1], listeners, err)
+		}
+		if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+			t.Errorf("expected LISTEN_FDS to be unset")
+		}
+	}
+
+	// Запуск процесса с двумя сокетами. LISTEN_PID должен совпадать с PID процесса, поэтому задается
+	// оболочкой, которая затем заменяется тестовым бинарным файлом с тем же PID.
+	var files []*os.File
+	var want []string
+	for _, name := range []string{"http", ""} {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer ln.Close()
+
+		file, err := ln.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer file.Close()
+
+		files = append(files, file)
+		if name == "" {
+			name = "unknown"
+		}
+		want = append(want, name+"="+ln.Addr().String())
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", `LISTEN_PID=$$ exec "$0"`, os.Args[0])
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(), envTestChild+"=1", "LISTEN_FDS=2", "LISTEN_FDNAMES=http:")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("unexpected error: %v: %s", err, output)
+	}
+
+	// Переменные LISTEN_* удаляются после получения сокетов.
+	want = append(want, "LISTEN_FDS=")
+	if got := strings.Fields(string(output)); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// listenNotify создает сокет, имитирующий сокет уведомлений systemd, и задает NOTIFY_SOCKET.
+// Если abstract равно true, используется абстрактный сокет с префиксом "@".
+func listenNotify(t *testing.T, abstract bool) *net.UnixConn {
+	t.Helper()
+
+	name := t.TempDir() + "/notify.sock"
+	env := name
+	if abstract {
+		name = fmt.Sprintf("\x00apiapp-test-%d", os.Getpid())
+		env = "@" + name[1:]
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", env)
+	return conn
+}
+
+// readNotify возвращает следующее сообщение, полученное сокетом уведомлений.
+func readNotify(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(buf[:n])
+}
+
+// Тестирование отправки уведомлений о состоянии.
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify(Ready)
+	if sent || err != nil {
+		t.Errorf("expected no notification without NOTIFY_SOCKET, got %v, %v", sent, err)
+	}
+
+	for _, abstract := range []bool{false, true} {
+		conn := listenNotify(t, abstract)
+
+		for _, state := range []string{Ready, "MAINPID=42", Stopping} {
+			sent, err := Notify(state)
+			if !sent || err != nil {
+				t.Fatalf("unexpected result %v, %v", sent, err)
+			}
+			if got := readNotify(t, conn); got != state {
+				t.Errorf("expected %q, got %q", state, got)
+			}
+		}
+	}
+
+	// Сокет уведомлений недоступен.
+	t.Setenv("NOTIFY_SOCKET", t.TempDir()+"/missing.sock")
+	if sent, err := Notify(Ready); sent || err == nil {
+		t.Errorf("expected error for a missing socket, got %v, %v", sent, err)
+	}
+}
+
+// Тестирование разбора настроек watchdog.
+func TestWatchdogInterval(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		usec    string
+		pid     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", "", 0, false},
+		{"30000000", "", 30 * time.Second, false},
+		{"500000", pid, 500 * time.Millisecond, false},
+		{"500000", "1", 0, false},
+		{"500000", "me", 0, true},
+		{"soon", "", 0, true},
+		{"0", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("WATCHDOG_USEC", tt.usec)
+		t.Setenv("WATCHDOG_PID", tt.pid)
+
+		got, err := WatchdogInterval()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("WATCHDOG_USEC=%q WATCHDOG_PID=%q: unexpected result %v, %v", tt.usec, tt.pid, got, err)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+//Пакет upgrade реализует перезапуск приложения без простоя: новый процесс наследует открытые
+//слушающие сокеты старого, сообщает о готовности, после чего старый процесс завершает работу.
+
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Переменные окружения, через которые родительский процесс передает дочернему сведения о сокетах.
+const (
+	envListeners = "APIAPP_UPGRADE_LISTENERS" // JSON-массив ключей унаследованных сокетов в порядке дескрипторов.
+	envReadyFD   = "APIAPP_UPGRADE_READY_FD"  // Номер дескриптора канала для сообщения о готовности.
+)
+
+// firstInheritedFD - номер первого дескриптора, переданного через exec.Cmd.ExtraFiles.
+const firstInheritedFD = 3
+
+// ErrInProgress возвращается при попытке начать перезапуск, пока предыдущий еще не завершен.
+var ErrInProgress = errors.New("upgrade: upgrade already in progress")
+
+// filer реализуется слушателями, дескриптор которых можно передать дочернему процессу.
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Upgrader управляет слушающими сокетами процесса и их передачей новому процессу.
+type Upgrader struct {
+	mu        sync.Mutex
+	inherited map[string]net.Listener // Унаследованные от родителя, но еще не запрошенные сокеты.
+	listeners map[string]net.Listener // Сокеты, используемые текущим процессом.
+	order     []string
+	readyFile *os.File
+	upgrading bool
+}
+
+// New создает Upgrader и подхватывает сокеты, унаследованные от родительского процесса, если он их передал.
+func New() (*Upgrader, error) {
+	u := &Upgrader{
+		inherited: make(map[string]net.Listener),
+		listeners: make(map[string]net.Listener),
+	}
+
+	// Процесс запущен не в результате перезапуска.
+	value, ok := os.LookupEnv(envListeners)
+	if !ok {
+		return u, nil
+	}
+
+	// Переменные окружения не должны передаваться процессам, которые запустит приложение.
+	os.Unsetenv(envListeners)
+	defer os.Unsetenv(envReadyFD)
+
+	var keys []string
+	err := json.Unmarshal([]byte(value), &keys)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: invalid %s: %w", envListeners, err)
+	}
+
+	// Восстановление слушателей из унаследованных дескрипторов.
+	for i, key := range keys {
+		file := os.NewFile(uintptr(firstInheritedFD+i), key)
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("upgrade: inherit listener %s: %w", key, err)
+		}
+		u.inherited[key] = ln
+	}
+
+	// Дескриптор канала, через который родитель ожидает сообщения о готовности.
+	fd, err := strconv.Atoi(os.Getenv(envReadyFD))
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: invalid %s: %w", envReadyFD, err)
+	}
+	u.readyFile = os.NewFile(uintptr(fd), "ready")
+
+	return u, nil
+}
+
+// Inherited возвращает true, если процесс запущен родителем в ходе перезапуска.
+func (u *Upgrader) Inherited() bool {
+	return u.readyFile != nil
+}
+
+// Listen возвращает унаследованный слушатель для указанных сети и адреса, а если его нет - открывает новый.
+// Все слушатели, полученные через Listen, передаются новому процессу при перезапуске.
+func (u *Upgrader) Listen(network, address string) (net.Listener, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := network + ":" + address
+
+	ln, ok := u.inherited[key]
+	if ok {
+		delete(u.inherited, key)
+	} else {
+		// Удаление файла сокета Unix, оставшегося после аварийного завершения предыдущего процесса.
+		if network == "unix" {
+			removeStaleSocket(address)
+		}
+
+		var err error
+		ln, err = net.Listen(network, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	u.listeners[key] = ln
+	u.order = append(u.order, key)
+
+	return ln, nil
+}
+
+// Adopt регистрирует слушатель, открытый вне Upgrader (например, переданный systemd), под ключом key,
+// чтобы он передавался новому процессу при перезапуске.
+func (u *Upgrader) Adopt(key string, ln net.Listener) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.listeners[key] = ln
+	u.order = append(u.order, key)
+}
+
+// TakeInherited возвращает унаследованные слушатели, ключи которых начинаются с prefix,
+// и регистрирует их как используемые текущим процессом.
+func (u *Upgrader) TakeInherited(prefix string) map[string]net.Listener {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	listeners := make(map[string]net.Listener)
+	for key, ln := range u.inherited {
+		if strings.HasPrefix(key, prefix) {
+			delete(u.inherited, key)
+			u.listeners[key] = ln
+			u.order = append(u.order, key)
+			listeners[key] = ln
+		}
+	}
+
+	return listeners
+}
+
+// Ready сообщает родительскому процессу, что новый процесс готов принимать соединения.
+// Унаследованные, но не запрошенные через Listen сокеты закрываются. Для процесса, запущенного
+// не в результате перезапуска, вызов ничего не делает.
+func (u *Upgrader) Ready() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for key, ln := range u.inherited {
+		ln.Close()
+		delete(u.inherited, key)
+	}
+
+	if u.readyFile == nil {
+		return nil
+	}
+
+	_, err := u.readyFile.Write([]byte{1})
+	u.readyFile.Close()
+	u.readyFile = nil
+
+	return err
+}
+
+// Upgrade запускает новый экземпляр исполняемого файла с теми же аргументами, передает ему слушающие
+// сокеты и ожидает сообщения о готовности. Если новый процесс завершился или не сообщил о готовности
+// до отмены ctx, он останавливается, а текущий процесс продолжает работу.
+func (u *Upgrader) Upgrade(ctx context.Context) (*os.Process, error) {
+	u.mu.Lock()
+	if u.upgrading {
+		u.mu.Unlock()
+		return nil, ErrInProgress
+	}
+	u.upgrading = true
+	u.mu.Unlock()
+
+	defer func() {
+		u.mu.Lock()
+		u.upgrading = false
+		u.mu.Unlock()
+	}()
+
+	// Поиск исполняемого файла по пути запуска, чтобы подхватить обновленный бинарный файл.
+	path, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: locate executable: %w", err)
+	}
+
+	// Дублирование дескрипторов слушающих сокетов для передачи дочернему процессу.
+	files, keys, err := u.files()
+	if err != nil {
+		return nil, err
+	}
+	defer closeFiles(files)
+
+	// Канал, через который дочерний процесс сообщит о готовности.
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: create ready pipe: %w", err)
+	}
+	defer readyReader.Close()
+
+	encodedKeys, err := json.Marshal(keys)
+	if err != nil {
+		readyWriter.Close()
+		return nil, err
+	}
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = append(files, readyWriter)
+	cmd.Env = append(os.Environ(),
+		envListeners+"="+string(encodedKeys),
+		envReadyFD+"="+strconv.Itoa(firstInheritedFD+len(files)),
+	)
+
+	err = cmd.Start()
+	readyWriter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: start process: %w", err)
+	}
+
+	// Ожидание сообщения о готовности. Закрытие канала без записи означает, что процесс завершился.
+	readyChan := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := readyReader.Read(buf)
+		readyChan <- err
+	}()
+
+	select {
+	case err = <-readyChan:
+		if err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return nil, errors.New("upgrade: new process exited before becoming ready")
+		}
+	case <-ctx.Done():
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("upgrade: waiting for new process: %w", ctx.Err())
+	}
+
+	// Сокеты Unix теперь принадлежат новому процессу, поэтому их файлы не должны удаляться при закрытии.
+	u.mu.Lock()
+	for _, ln := range u.listeners {
+		if unixListener, ok := ln.(*net.UnixListener); ok {
+			unixListener.SetUnlinkOnClose(false)
+		}
+	}
+	u.mu.Unlock()
+
+	// Процесс продолжает работу после завершения текущего, поэтому освобождаем ресурсы ожидания.
+	go cmd.Wait()
+
+	return cmd.Process, nil
+}
+
+// files возвращает копии дескрипторов слушающих сокетов и их ключи в порядке открытия.
+func (u *Upgrader) files() ([]*os.File, []string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var files []*os.File
+	var keys []string
+
+	for _, key := range u.order {
+		f, ok := u.listeners[key].(filer)
+		if !ok {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("upgrade: listener %s cannot be inherited", key)
+		}
+
+		file, err := f.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("upgrade: duplicate listener %s: %w", key, err)
+		}
+
+		files = append(files, file)
+		keys = append(keys, key)
+	}
+
+	return files, keys, nil
+}
+
+// removeStaleSocket удаляет файл по пути path, если это сокет Unix, который никто не слушает. Файлы других
+// типов не затрагиваются, чтобы ошибка в конфигурации не привела к удалению данных, а сокет работающего
+// процесса остается на месте, и открытие слушателя завершится ошибкой "address already in use".
+func removeStaleSocket(path string) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		os.Remove(path)
+	}
+}
+
+// closeFiles закрывает все переданные файлы.
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
@@ -0,0 +1,229 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// envTestChild - переменная окружения, по которой тестовый бинарный файл, запущенный через Upgrade,
+// выполняет роль нового процесса вместо запуска тестов.
+const envTestChild = "UPGRADE_TEST_CHILD"
+
+// TestMain выполняет роль нового процесса, если тестовый бинарный файл запущен через Upgrade.
+func TestMain(m *testing.M) {
+	if address := os.Getenv(envTestChild); address != "" {
+		os.Exit(runChild(address))
+	}
+	os.Exit(m.Run())
+}
+
+// runChild принимает унаследованный сокет по адресу address, сообщает о готовности
+// и отвечает на одно соединение строкой с PID процесса.
+func runChild(address string) int {
+	u, err := New()
+	if err != nil || !u.Inherited() {
+		fmt.Fprintln(os.Stderr, "child: not inherited:", err)
+		return 1
+	}
+
+	ln, err := u.Listen("tcp", address)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "child:", err)
+		return 1
+	}
+
+	err = u.Ready()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "child:", err)
+		return 1
+	}
+
+	conn, err := ln.Accept()
+	if err != nil {
+		return 1
+	}
+	fmt.Fprintf(conn, "%d", os.Getpid())
+	conn.Close()
+	return 0
+}
+
+// Тестирование разбора переменных окружения, переданных родительским процессом.
+func TestNew(t *testing.T) {
+	// Процесс, запущенный не в результате перезапуска.
+	u, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.Inherited() {
+		t.Errorf("expected a process without inherited listeners")
+	}
+	if err := u.Ready(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		listeners string
+		readyFD   string
+	}{
+		{"not json", "3"},
+		{`[]`, "ready"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(envListeners, tt.listeners)
+		t.Setenv(envReadyFD, tt.readyFD)
+
+		_, err := New()
+		if err == nil {
+			t.Errorf("%s, %s: expected error", tt.listeners, tt.readyFD)
+		}
+		if _, ok := os.LookupEnv(envListeners); ok {
+			t.Errorf("expected %s to be unset", envListeners)
+		}
+	}
+}
+
+// Тестирование учета слушающих сокетов.
+func TestListen(t *testing.T) {
+	u, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tcp, err := u.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tcp.Close()
+
+	unix, err := u.Listen("unix", t.TempDir()+"/api.sock")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unix.Close()
+
+	adopted, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer adopted.Close()
+	u.Adopt("systemd:0:http", adopted)
+
+	// Дескрипторы передаются в порядке открытия сокетов.
+	files, keys, err := u.files()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer closeFiles(files)
+
+	want := []string{"tcp:127.0.0.1:0", "unix:" + unix.Addr().String(), "systemd:0:http"}
+	if len(keys) != len(want) || len(files) != len(want) {
+		t.Fatalf("expected keys %v, got %v", want, keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("expected keys %v, got %v", want, keys)
+			break
+		}
+	}
+}
+
+// Тестирование передачи сокета новому процессу.
+func TestUpgrade(t *testing.T) {
+	u, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ln, err := u.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	address := ln.Addr().String()
+
+	// Новый процесс запрашивает сокет по тому же ключу, что и текущий.
+	t.Setenv(envTestChild, "127.0.0.1:0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	process, err := u.Upgrade(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// После закрытия сокета в текущем процессе соединения принимает новый процесс.
+	ln.Close()
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	pid, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(pid) != fmt.Sprint(process.Pid) {
+		t.Errorf("expected response from process %d, got %q", process.Pid, pid)
+	}
+}
+
+// Тестирование удаления файла сокета Unix, оставшегося после аварийного завершения.
+func TestListenStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+
+	// Файл сокета, который никто не слушает, удаляется.
+	stale, err := net.Listen("unix", dir+"/stale.sock")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	u, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ln, err := u.Listen("unix", dir+"/stale.sock")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ln.Close()
+
+	// Сокет работающего процесса не удаляется.
+	active, err := net.Listen("unix", dir+"/active.sock")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer active.Close()
+
+	_, err = u.Listen("unix", dir+"/active.sock")
+	if err == nil {
+		t.Errorf("expected error for a socket in use")
+	}
+	if conn, err := net.Dial("unix", dir+"/active.sock"); err != nil {
+		t.Errorf("expected the active socket to be kept, got %v", err)
+	} else {
+		conn.Close()
+	}
+
+	// Обычный файл не удаляется.
+	err = os.WriteFile(dir+"/data", []byte("data"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = u.Listen("unix", dir+"/data"); err == nil {
+		t.Errorf("expected error for a regular file")
+	}
+	if _, err := os.Stat(dir + "/data"); err != nil {
+		t.Errorf("expected the regular file to be kept, got %v", err)
+	}
+}
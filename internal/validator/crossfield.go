@@ -0,0 +1,238 @@
+//Код содержит правила для тегов validate, которые сравнивают поле с другими полями той же структуры
+//и делают поле обязательным в зависимости от значений других полей.
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+func init() {
+	registerCompareRule("eqfield", "mismatch", "must be equal to %s", func(c int) bool { return c == 0 })
+	registerCompareRule("nefield", "must_differ", "must not be equal to %s", func(c int) bool { return c != 0 })
+	registerCompareRule("gtfield", "too_small", "must be greater than %s", func(c int) bool { return c > 0 })
+	registerCompareRule("gtefield", "too_small", "must be greater than or equal to %s", func(c int) bool { return c >= 0 })
+	registerCompareRule("ltfield", "too_large", "must be less than %s", func(c int) bool { return c < 0 })
+	registerCompareRule("ltefield", "too_large", "must be less than or equal to %s", func(c int) bool { return c <= 0 })
+
+	// required_if=Country DE: поле обязательно, если поле Country равно DE (допускается несколько пар).
+	registerRule("required_if", rule{
+		check: func(f Field) bool {
+			return !fieldsEqual(f) || ruleRequired(f)
+		},
+		code: constCode("required"),
+		message: func(f Field) string {
+			return "must be provided when " + describePairs(f, "is")
+		},
+		always: true,
+	})
+
+	// required_unless=Country DE: поле обязательно, если поле Country не равно DE.
+	registerRule("required_unless", rule{
+		check: func(f Field) bool {
+			return fieldsEqual(f) || ruleRequired(f)
+		},
+		code: constCode("required"),
+		message: func(f Field) string {
+			return "must be provided unless " + describePairs(f, "is")
+		},
+		always: true,
+	})
+
+	// required_with=A B: поле обязательно, если задано хотя бы одно из полей A, B.
+	registerRule("required_with", rule{
+		check: func(f Field) bool {
+			return countPresent(f) == 0 || ruleRequired(f)
+		},
+		code: constCode("required"),
+		message: func(f Field) string {
+			return fmt.Sprintf("must be provided when %s is provided", describeFields(f, "or"))
+		},
+		always: true,
+	})
+
+	// required_without=A B: поле обязательно, если не задано хотя бы одно из полей A, B.
+	registerRule("required_without", rule{
+		check: func(f Field) bool {
+			return countPresent(f) == len(strings.Fields(f.Param)) || ruleRequired(f)
+		},
+		code: constCode("required"),
+		message: func(f Field) string {
+			return fmt.Sprintf("must be provided when %s is not provided", describeFields(f, "or"))
+		},
+		always: true,
+	})
+
+	// required_without_all=A B: поле обязательно, если не задано ни одно из полей A, B
+	// (то есть должно быть задано хотя бы одно поле из группы).
+	registerRule("required_without_all", rule{
+		check: func(f Field) bool {
+			return countPresent(f) > 0 || ruleRequired(f)
+		},
+		code: constCode("required"),
+		message: func(f Field) string {
+			return fmt.Sprintf("must be provided when %s is not provided", describeFields(f, "and"))
+		},
+		always: true,
+	})
+
+	// excluded_with=A B: поле должно быть пустым, если задано хотя бы одно из полей A, B (взаимоисключающие поля).
+	registerRule("excluded_with", rule{
+		check: func(f Field) bool {
+			return countPresent(f) == 0 || !ruleRequired(f)
+		},
+		code: constCode("not_allowed"),
+		message: func(f Field) string {
+			return fmt.Sprintf("must not be provided together with %s", describeFields(f, "or"))
+		},
+		always: true,
+	})
+}
+
+// registerCompareRule регистрирует правило, сравнивающее поле с другим полем структуры.
+func registerCompareRule(name, code, message string, ok func(c int) bool) {
+	registerRule(name, rule{
+		check: func(f Field) bool {
+			// Незаданное поле (nil-указатель) не сравнивается: его наличие проверяют правила required_*.
+			other, _ := sibling(f, f.Param)
+			if !other.IsValid() {
+				return true
+			}
+
+			c, comparable := compareValues(f.Value, other)
+			return comparable && ok(c)
+		},
+		code: constCode(code),
+		message: func(f Field) string {
+			return fmt.Sprintf(message, siblingName(f, f.Param))
+		},
+		params: func(f Field) map[string]string {
+			return map[string]string{"field": siblingName(f, f.Param)}
+		},
+	})
+}
+
+// sibling возвращает значение поля структуры f.Parent по имени поля Go или имени из тега json.
+// Необязательные значения (например, request.Optional) разворачиваются так же, как при проверке самого поля.
+func sibling(f Field, name string) (reflect.Value, bool) {
+	field, found := siblingField(f, name)
+	if !found {
+		// Исключение в случае некорректного использования API: ссылка на несуществующее поле - ошибка в теге.
+		panic(fmt.Sprintf("validator: unknown field %q in rule parameter", name))
+	}
+
+	return indirect(unwrapOptional(f.Parent.FieldByIndex(field.Index))), true
+}
+
+// siblingField находит описание поля структуры f.Parent по имени поля Go или имени из тега json.
+func siblingField(f Field, name string) (reflect.StructField, bool) {
+	if !f.Parent.IsValid() || f.Parent.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	t := f.Parent.Type()
+	if field, found := t.FieldByName(name); found {
+		return field, true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if jsonName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// siblingName возвращает ключ поля (имя из тега json), под которым оно видно клиенту.
+func siblingName(f Field, name string) string {
+	field, found := siblingField(f, name)
+	if !found {
+		return name
+	}
+	return jsonName(field)
+}
+
+// fieldsEqual проверяет условия вида "Поле значение [Поле значение ...]" из параметра правила.
+func fieldsEqual(f Field) bool {
+	parts := strings.Fields(f.Param)
+	for i := 0; i+1 < len(parts); i += 2 {
+		other, _ := sibling(f, parts[i])
+		if formatValue(other) != parts[i+1] {
+			return false
+		}
+	}
+	return true
+}
+
+// countPresent возвращает количество заданных (непустых) полей из списка в параметре правила.
+func countPresent(f Field) int {
+	count := 0
+	for _, name := range strings.Fields(f.Param) {
+		other, _ := sibling(f, name)
+		if ruleRequired(Field{Value: other}) {
+			count++
+		}
+	}
+	return count
+}
+
+// describePairs формирует описание условий вида "country is DE" для сообщения об ошибке.
+func describePairs(f Field, verb string) string {
+	parts := strings.Fields(f.Param)
+
+	var conditions []string
+	for i := 0; i+1 < len(parts); i += 2 {
+		conditions = append(conditions, fmt.Sprintf("%s %s %s", siblingName(f, parts[i]), verb, parts[i+1]))
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// describeFields формирует список имен полей для сообщения об ошибке.
+func describeFields(f Field, conjunction string) string {
+	var names []string
+	for _, name := range strings.Fields(f.Param) {
+		names = append(names, siblingName(f, name))
+	}
+	return strings.Join(names, " "+conjunction+" ")
+}
+
+// compareValues сравнивает два значения одного вида: числа, строки или time.Time.
+// Возвращает -1, 0 или 1 и false, если значения нельзя сравнить.
+func compareValues(a, b reflect.Value) (int, bool) {
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	}
+
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok {
+			return 0, false
+		}
+		return compareOrdered(x, y), true
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+
+	return 0, false
+}
+
+// compareOrdered сравнивает два числа и возвращает -1, 0 или 1.
+func compareOrdered(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
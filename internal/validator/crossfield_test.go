@@ -0,0 +1,196 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"apiapp/internal/request"
+)
+
+// Тестирование правил сравнения полей.
+func TestCompareRules(t *testing.T) {
+	type input struct {
+		Password string     `json:"password"`
+		Confirm  string     `json:"confirm" validate:"eqfield=Password"`
+		Username string     `json:"username"`
+		Nickname string     `json:"nickname" validate:"omitempty,nefield=username"`
+		Min      int        `json:"min"`
+		Max      int64      `json:"max" validate:"gtefield=Min"`
+		Limit    *float64   `json:"limit" validate:"ltfield=Max"`
+		StartsAt time.Time  `json:"starts_at"`
+		EndsAt   time.Time  `json:"ends_at" validate:"gtfield=StartsAt"`
+		Deadline *time.Time `json:"deadline"`
+		RemindAt time.Time  `json:"remind_at" validate:"omitempty,ltefield=Deadline"`
+	}
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+	limit := 20.0
+
+	valid := input{
+		Password: "secret", Confirm: "secret",
+		Username: "alice", Nickname: "ally",
+		Min: 1, Max: 10,
+		StartsAt: now, EndsAt: later,
+		Deadline: &later, RemindAt: now,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*input)
+		want   map[string][]FieldError
+	}{
+		{"корректные значения", func(in *input) {}, nil},
+		{"равные границы", func(in *input) { in.Max = 1; in.RemindAt = later }, nil},
+		{
+			"пароли не совпадают",
+			func(in *input) { in.Confirm = "other" },
+			map[string][]FieldError{"confirm": {{Code: "mismatch", Message: "must be equal to password", Params: map[string]string{"field": "password"}}}},
+		},
+		{
+			"одинаковые имена",
+			func(in *input) { in.Nickname = "alice" },
+			map[string][]FieldError{"nickname": {{Code: "must_differ", Message: "must not be equal to username", Params: map[string]string{"field": "username"}}}},
+		},
+		{
+			"неверный диапазон",
+			func(in *input) { in.Max = 0; in.Limit = &limit },
+			map[string][]FieldError{
+				"max":   {{Code: "too_small", Message: "must be greater than or equal to min", Params: map[string]string{"field": "min"}}},
+				"limit": {{Code: "too_large", Message: "must be less than max", Params: map[string]string{"field": "max"}}},
+			},
+		},
+		{
+			"неверный интервал времени",
+			func(in *input) { in.EndsAt = now; in.RemindAt = later.Add(time.Second) },
+			map[string][]FieldError{
+				"ends_at":   {{Code: "too_small", Message: "must be greater than starts_at", Params: map[string]string{"field": "starts_at"}}},
+				"remind_at": {{Code: "too_large", Message: "must be less than or equal to deadline", Params: map[string]string{"field": "deadline"}}},
+			},
+		},
+		// Незаданное поле, с которым сравнивается значение, не приводит к ошибке сравнения.
+		{"незаданное поле для сравнения", func(in *input) { in.Deadline = nil }, nil},
+	}
+
+	for _, tt := range tests {
+		in := valid
+		tt.modify(&in)
+
+		v := ValidateStruct(in)
+		if !reflect.DeepEqual(v.Details, tt.want) {
+			t.Errorf("%s:\n got: %+v\nwant: %+v", tt.name, v.Details, tt.want)
+		}
+	}
+}
+
+// Тестирование правил условной обязательности полей.
+func TestRequiredRules(t *testing.T) {
+	type input struct {
+		Country  string  `json:"country"`
+		VATID    string  `json:"vat_id" validate:"required_if=Country DE"`
+		State    string  `json:"state" validate:"required_unless=country DE"`
+		Street   string  `json:"street"`
+		City     string  `json:"city" validate:"required_with=Street"`
+		Email    string  `json:"email"`
+		Phone    *string `json:"phone" validate:"required_without=Email"`
+		Telegram string  `json:"telegram" validate:"required_without_all=Email Phone"`
+		Card     string  `json:"card"`
+		Invoice  string  `json:"invoice" validate:"excluded_with=Card"`
+	}
+
+	phone := "+14155552671"
+
+	tests := []struct {
+		name  string
+		input input
+		want  map[string][]string
+	}{
+		{
+			name:  "все условия выполнены",
+			input: input{Country: "DE", VATID: "DE123", Street: "Main", City: "Berlin", Email: "a@example.com", Invoice: "INV-1"},
+			want:  map[string][]string{},
+		},
+		{
+			name:  "условия не выполнены",
+			input: input{Country: "DE", Street: "Main", Card: "4242", Invoice: "INV-1"},
+			want: map[string][]string{
+				"vat_id":   {"required"},
+				"city":     {"required"},
+				"phone":    {"required"},
+				"telegram": {"required"},
+				"invoice":  {"not_allowed"},
+			},
+		},
+		{
+			name:  "другая страна",
+			input: input{Country: "FR", Email: "a@example.com"},
+			want:  map[string][]string{"state": {"required"}},
+		},
+		{
+			name:  "телефон вместо почты",
+			input: input{Country: "DE", VATID: "DE123", Phone: &phone},
+			want:  map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		v := ValidateStruct(tt.input)
+		if got := codes(v); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got: %v\nwant: %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Сообщения называют поля по именам из тега json.
+	v := ValidateStruct(input{Country: "FR", Street: "Main", Card: "4242", Invoice: "INV-1"})
+	want := map[string]string{
+		"state":    "must be provided unless country is DE",
+		"city":     "must be provided when street is provided",
+		"phone":    "must be provided when email is not provided",
+		"telegram": "must be provided when email and phone is not provided",
+		"invoice":  "must not be provided together with card",
+	}
+	if !reflect.DeepEqual(v.FieldErrors, want) {
+		t.Errorf("unexpected messages:\n got: %v\nwant: %v", v.FieldErrors, want)
+	}
+
+	// Ссылка на несуществующее поле - ошибка в теге.
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for an unknown field")
+		}
+	}()
+	ValidateStruct(struct {
+		Name string `validate:"required_with=Missing"`
+	}{})
+}
+
+// Тестирование правил для полей, которые ссылаются на необязательные значения.
+func TestCrossFieldOptional(t *testing.T) {
+	type input struct {
+		Min    request.Optional[int]    `json:"min"`
+		Max    request.Optional[int]    `json:"max" validate:"omitempty,gtfield=Min"`
+		Coupon request.Optional[string] `json:"coupon"`
+		Code   string                   `json:"code" validate:"required_with=Coupon"`
+		Gift   string                   `json:"gift" validate:"excluded_with=Coupon"`
+	}
+
+	tests := []struct {
+		name  string
+		input input
+		want  map[string][]string
+	}{
+		{"значения сравниваются без обертки", input{Min: request.Some(1), Max: request.Some(5)}, map[string][]string{}},
+		{"неверный диапазон", input{Min: request.Some(5), Max: request.Some(1)}, map[string][]string{"max": {"too_small"}}},
+		{"отсутствующее поле не сравнивается", input{Max: request.Some(1)}, map[string][]string{}},
+		{"null не сравнивается", input{Min: request.Optional[int]{Set: true, Null: true}, Max: request.Some(1)}, map[string][]string{}},
+		{"заданное поле", input{Coupon: request.Some("SALE"), Gift: "card"}, map[string][]string{"code": {"required"}, "gift": {"not_allowed"}}},
+		{"null не считается заданным", input{Coupon: request.Optional[string]{Set: true, Null: true}, Gift: "card"}, map[string][]string{}},
+	}
+
+	for _, tt := range tests {
+		if got := codes(ValidateStruct(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got: %v\nwant: %v", tt.name, got, tt.want)
+		}
+	}
+}
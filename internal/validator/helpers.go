@@ -3,9 +3,15 @@
 package validator
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"net/netip"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"golang.org/x/exp/constraints"
@@ -98,3 +104,307 @@ func IsURL(value string) bool {
 
 	return u.Scheme != "" && u.Host != ""
 }
+
+var (
+	// RgxULID - регулярное выражение для валидации ULID (алфавит Crockford Base32 без I, L, O, U).
+	RgxULID = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+
+	// RgxE164 - регулярное выражение для валидации телефонного номера в формате E.164.
+	RgxE164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+	// RgxHostname - регулярное выражение для валидации метки имени хоста (RFC 1123).
+	RgxHostname = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+	// RgxISODuration - регулярное выражение для валидации продолжительности в формате ISO 8601.
+	RgxISODuration = regexp.MustCompile(`^P(?:\d+(?:[.,]\d+)?W|(?:\d+(?:[.,]\d+)?Y)?(?:\d+(?:[.,]\d+)?M)?(?:\d+(?:[.,]\d+)?D)?(?:T(?:\d+(?:[.,]\d+)?H)?(?:\d+(?:[.,]\d+)?M)?(?:\d+(?:[.,]\d+)?S)?)?)$`)
+
+	// RgxSemver - регулярное выражение для валидации семантической версии (semver 2.0.0).
+	RgxSemver = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+	// RgxHexColor - регулярное выражение для валидации цвета в шестнадцатеричном формате (#rgb, #rgba, #rrggbb, #rrggbbaa).
+	RgxHexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+	// RgxLanguage - регулярное выражение для валидации кода языка ISO 639-1 с необязательным регионом (en, en-US).
+	RgxLanguage = regexp.MustCompile(`^[a-z]{2}(?:-[A-Z]{2})?$`)
+)
+
+// IsUUID возвращает true, если значение строки представляет собой корректный UUID.
+// Если указаны версии, UUID также должен иметь одну из них.
+func IsUUID(value string, versions ...int) bool {
+	if len(value) != 36 {
+		return false
+	}
+
+	for i, c := range value {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(c) {
+				return false
+			}
+		}
+	}
+
+	// Вариант RFC 4122 задается старшими битами 17-го символа (8, 9, a или b), кроме нулевого UUID.
+	if value == "00000000-0000-0000-0000-000000000000" {
+		return len(versions) == 0
+	}
+	if !strings.ContainsRune("89abAB", rune(value[19])) {
+		return false
+	}
+
+	if len(versions) == 0 {
+		return true
+	}
+
+	version, _ := strconv.ParseInt(value[14:15], 16, 0)
+	return In(int(version), versions...)
+}
+
+// IsULID возвращает true, если значение строки представляет собой корректный ULID.
+func IsULID(value string) bool {
+	return RgxULID.MatchString(value)
+}
+
+// IsE164 возвращает true, если значение строки представляет собой телефонный номер в формате E.164 (например, +14155552671).
+func IsE164(value string) bool {
+	return RgxE164.MatchString(value)
+}
+
+// IsIP возвращает true, если значение строки представляет собой корректный IPv4- или IPv6-адрес.
+func IsIP(value string) bool {
+	_, err := netip.ParseAddr(value)
+	return err == nil
+}
+
+// IsIPv4 возвращает true, если значение строки представляет собой корректный IPv4-адрес.
+func IsIPv4(value string) bool {
+	addr, err := netip.ParseAddr(value)
+	return err == nil && addr.Is4()
+}
+
+// IsIPv6 возвращает true, если значение строки представляет собой корректный IPv6-адрес.
+func IsIPv6(value string) bool {
+	addr, err := netip.ParseAddr(value)
+	return err == nil && addr.Is6()
+}
+
+// IsCIDR возвращает true, если значение строки представляет собой корректную подсеть в нотации CIDR.
+func IsCIDR(value string) bool {
+	_, err := netip.ParsePrefix(value)
+	return err == nil
+}
+
+// IsHostname возвращает true, если значение строки представляет собой корректное имя хоста (RFC 1123).
+func IsHostname(value string) bool {
+	value = strings.TrimSuffix(value, ".")
+	if value == "" || len(value) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(value, ".") {
+		if !RgxHostname.MatchString(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsISODate возвращает true, если значение строки представляет собой дату в формате ISO 8601 (2006-01-02).
+func IsISODate(value string) bool {
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
+
+// IsISODateTime возвращает true, если значение строки представляет собой дату и время в формате RFC 3339.
+func IsISODateTime(value string) bool {
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// IsISODuration возвращает true, если значение строки представляет собой продолжительность в формате ISO 8601 (например, P1DT2H).
+func IsISODuration(value string) bool {
+	return value != "P" && !strings.HasSuffix(value, "T") && RgxISODuration.MatchString(value)
+}
+
+// IsCountryCode возвращает true, если значение строки представляет собой код страны ISO 3166-1 alpha-2 (например, DE).
+func IsCountryCode(value string) bool {
+	return countryCodes[value]
+}
+
+// IsCurrencyCode возвращает true, если значение строки представляет собой код валюты ISO 4217 (например, EUR).
+func IsCurrencyCode(value string) bool {
+	return currencyCodes[value]
+}
+
+// IsLanguageCode возвращает true, если значение строки представляет собой код языка ISO 639-1
+// с необязательным кодом региона (например, en или en-US).
+func IsLanguageCode(value string) bool {
+	if !RgxLanguage.MatchString(value) {
+		return false
+	}
+
+	language, region, found := strings.Cut(value, "-")
+	return languageCodes[language] && (!found || countryCodes[region])
+}
+
+// IsIBAN возвращает true, если значение строки представляет собой корректный IBAN (проверяется контрольная сумма по модулю 97).
+// Пробелы между группами символов допускаются.
+func IsIBAN(value string) bool {
+	value = strings.ReplaceAll(value, " ", "")
+	if len(value) < 15 || len(value) > 34 || !countryCodes[value[:2]] {
+		return false
+	}
+
+	// Перенос первых четырех символов в конец и замена букв числами (A = 10, ..., Z = 35).
+	rearranged := value[4:] + value[:4]
+	remainder := 0
+	for _, c := range rearranged {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+
+	return remainder == 1
+}
+
+// IsLuhn возвращает true, если строка из цифр проходит проверку по алгоритму Луна (например, номер банковской карты).
+// Пробелы и дефисы между группами цифр допускаются.
+func IsLuhn(value string) bool {
+	value = strings.NewReplacer(" ", "", "-", "").Replace(value)
+	if len(value) < 2 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// IsCreditCard возвращает true, если значение строки похоже на номер банковской карты: 12-19 цифр с корректной контрольной суммой.
+func IsCreditCard(value string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(value)
+	return Between(len(digits), 12, 19) && IsLuhn(digits)
+}
+
+// IsSemver возвращает true, если значение строки представляет собой семантическую версию (например, 1.2.3-rc.1+build.5).
+func IsSemver(value string) bool {
+	return RgxSemver.MatchString(value)
+}
+
+// IsHexColor возвращает true, если значение строки представляет собой цвет в шестнадцатеричном формате (например, #ff8800).
+func IsHexColor(value string) bool {
+	return RgxHexColor.MatchString(value)
+}
+
+// IsBase64 возвращает true, если значение строки представляет собой корректные данные в кодировке Base64
+// (стандартный или URL-safe алфавит, с дополнением или без).
+func IsBase64(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		_, err := encoding.DecodeString(value)
+		if err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsJSON возвращает true, если значение строки представляет собой корректный JSON.
+func IsJSON(value string) bool {
+	return json.Valid([]byte(value))
+}
+
+// PasswordStrength оценивает сложность пароля по шкале от 0 (очень слабый) до 4 (очень сложный)
+// с учетом длины, разнообразия классов символов и повторений.
+func PasswordStrength(value string) int {
+	length := utf8.RuneCountInString(value)
+	if length < 8 {
+		return 0
+	}
+
+	var lower, upper, digit, other bool
+	unique := make(map[rune]bool)
+	for _, r := range value {
+		unique[r] = true
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+
+	score := classes - 1
+	if length >= 12 {
+		score++
+	}
+	if length >= 16 {
+		score++
+	}
+
+	// Пароли из малого количества различных символов (например, "aaaaaaaaaaa1") считаются очень слабыми.
+	if len(unique) < length/3 {
+		score = 0
+	}
+
+	switch {
+	case score < 0:
+		return 0
+	case score > 4:
+		return 4
+	default:
+		return score
+	}
+}
+
+// IsStrongPassword возвращает true, если оценка сложности пароля не меньше minScore (от 0 до 4).
+func IsStrongPassword(value string, minScore int) bool {
+	return PasswordStrength(value) >= minScore
+}
+
+// isHexDigit возвращает true, если символ является шестнадцатеричной цифрой.
+func isHexDigit(c rune) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
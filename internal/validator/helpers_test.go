@@ -0,0 +1,149 @@
+package validator
+
+import "testing"
+
+// Тестирование функций проверки строковых значений.
+func TestStringHelpers(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(string) bool
+		value string
+		want  bool
+	}{
+		{"ULID", IsULID, "01ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+		{"ULID с недопустимым символом", IsULID, "01ARZ3NDEKTSV4RRFFQ69G5FAU", false},
+		{"ULID неверной длины", IsULID, "01ARZ3NDEKTSV4RRFFQ69G5FA", false},
+		{"E.164", IsE164, "+14155552671", true},
+		{"E.164 без плюса", IsE164, "14155552671", false},
+		{"E.164 с ведущим нулем", IsE164, "+04155552671", false},
+		{"IPv4", IsIPv4, "192.168.0.1", true},
+		{"IPv4 вне диапазона", IsIPv4, "256.1.1.1", false},
+		{"IPv6", IsIPv6, "2001:db8::1", true},
+		{"IPv6 для адреса IPv4", IsIPv6, "10.0.0.1", false},
+		{"IP", IsIP, "::1", true},
+		{"IP некорректный", IsIP, "localhost", false},
+		{"CIDR", IsCIDR, "10.0.0.0/8", true},
+		{"CIDR без маски", IsCIDR, "10.0.0.0", false},
+		{"Hostname", IsHostname, "api.example.com", true},
+		{"Hostname с подчеркиванием", IsHostname, "api_v1.example.com", false},
+		{"Hostname с дефисом в конце метки", IsHostname, "api-.example.com", false},
+		{"Дата", IsISODate, "2024-02-29", true},
+		{"Несуществующая дата", IsISODate, "2023-02-29", false},
+		{"Дата и время", IsISODateTime, "2024-01-02T15:04:05Z", true},
+		{"Дата и время без зоны", IsISODateTime, "2024-01-02T15:04:05", false},
+		{"Продолжительность", IsISODuration, "P1Y2M3DT4H5M6S", true},
+		{"Продолжительность в неделях", IsISODuration, "P2W", true},
+		{"Пустая продолжительность", IsISODuration, "P", false},
+		{"Продолжительность без времени после T", IsISODuration, "P1DT", false},
+		{"Код страны", IsCountryCode, "DE", true},
+		{"Несуществующий код страны", IsCountryCode, "XX", false},
+		{"Код валюты", IsCurrencyCode, "EUR", true},
+		{"Код валюты в нижнем регистре", IsCurrencyCode, "eur", false},
+		{"Код языка", IsLanguageCode, "en", true},
+		{"Код языка с регионом", IsLanguageCode, "en-US", true},
+		{"Код языка с неизвестным регионом", IsLanguageCode, "en-XX", false},
+		{"IBAN", IsIBAN, "DE89 3704 0044 0532 0130 00", true},
+		{"IBAN с неверной контрольной суммой", IsIBAN, "DE89370400440532013001", false},
+		{"Алгоритм Луна", IsLuhn, "79927398713", true},
+		{"Алгоритм Луна с ошибкой", IsLuhn, "79927398710", false},
+		{"Номер карты", IsCreditCard, "4111 1111 1111 1111", true},
+		{"Слишком короткий номер карты", IsCreditCard, "79927398713", false},
+		{"Семантическая версия", IsSemver, "1.2.3-rc.1+build.5", true},
+		{"Семантическая версия с префиксом", IsSemver, "v1.0.0", true},
+		{"Неполная семантическая версия", IsSemver, "1.2", false},
+		{"Цвет", IsHexColor, "#ff8800", true},
+		{"Короткий цвет", IsHexColor, "#f80", true},
+		{"Цвет без решетки", IsHexColor, "ff8800", false},
+		{"Base64", IsBase64, "aGVsbG8=", true},
+		{"Base64 без дополнения", IsBase64, "aGVsbG8", true},
+		{"Некорректный Base64", IsBase64, "not base64!", false},
+		{"JSON", IsJSON, `{"a":[1,2]}`, true},
+		{"Некорректный JSON", IsJSON, `{"a":`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fn(tt.value)
+			if got != tt.want {
+				t.Errorf("%q: expected %v, got %v", tt.value, tt.want, got)
+			}
+		})
+	}
+}
+
+// Тестирование функции IsUUID с проверкой версий.
+func TestIsUUID(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		versions []int
+		want     bool
+	}{
+		{"UUID v4", "f47ac10b-58cc-4372-a567-0e02b2c3d479", nil, true},
+		{"UUID v4 с проверкой версии", "f47ac10b-58cc-4372-a567-0e02b2c3d479", []int{4}, true},
+		{"UUID v4 при ожидании v7", "f47ac10b-58cc-4372-a567-0e02b2c3d479", []int{7}, false},
+		{"UUID v7", "018f3c4e-8b7a-7cde-9f01-23456789abcd", []int{4, 7}, true},
+		{"Нулевой UUID", "00000000-0000-0000-0000-000000000000", nil, true},
+		{"UUID с неверным вариантом", "f47ac10b-58cc-4372-c567-0e02b2c3d479", nil, false},
+		{"UUID без дефисов", "f47ac10b58cc4372a5670e02b2c3d479", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IsUUID(tt.value, tt.versions...)
+			if got != tt.want {
+				t.Errorf("%q: expected %v, got %v", tt.value, tt.want, got)
+			}
+		})
+	}
+}
+
+// Тестирование оценки сложности пароля.
+func TestPasswordStrength(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"short", 0},
+		{"password", 0},
+		{"aaaaaaaaaaaa1", 0},
+		{"Passw0rd", 2},
+		{"Correct-Horse-9", 4},
+		{"correct horse battery staple", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := PasswordStrength(tt.value)
+			if got != tt.want {
+				t.Errorf("%q: expected %d, got %d", tt.value, tt.want, got)
+			}
+		})
+	}
+}
+
+// Тестирование правил тегов validate для новых функций проверки.
+func TestStringRules(t *testing.T) {
+	var input struct {
+		ID       string `json:"id" validate:"uuid=4"`
+		Phone    string `json:"phone" validate:"e164"`
+		Country  string `json:"country" validate:"country"`
+		Password string `json:"password" validate:"password=3"`
+	}
+	input.ID = "018f3c4e-8b7a-7cde-9f01-23456789abcd"
+	input.Phone = "+14155552671"
+	input.Country = "DE"
+	input.Password = "password"
+
+	v := ValidateStruct(&input)
+
+	want := map[string]string{"id": "invalid_uuid", "password": "weak_password"}
+	if len(v.Details) != len(want) {
+		t.Fatalf("expected errors for %v, got %v", want, v.FieldErrors)
+	}
+	for key, code := range want {
+		if got := v.Details[key]; len(got) != 1 || got[0].Code != code {
+			t.Errorf("%s: expected code %q, got %v", key, code, got)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+//Код содержит встроенные правила для тегов validate, построенные на функциях из helpers.go.
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	registerRule("required", rule{
+		check:   ruleRequired,
+		message: constMessage("must be provided"),
+		always:  true,
+	})
+	registerRule("notblank", rule{
+		check: func(f Field) bool {
+			return f.Value.Kind() != reflect.String || NotBlank(f.Value.String())
+		},
+		code:    constCode("blank"),
+		message: constMessage("must not be blank"),
+	})
+	registerRule("min", rule{
+		check: ruleMin,
+		code:  sizeCode("too_short", "too_few", "too_small"),
+		message: func(f Field) string {
+			return sizeMessage(f, "at least")
+		},
+	})
+	registerRule("max", rule{
+		check: ruleMax,
+		code:  sizeCode("too_long", "too_many", "too_large"),
+		message: func(f Field) string {
+			return sizeMessage(f, "at most")
+		},
+	})
+	registerRule("len", rule{
+		check: ruleLen,
+		code:  sizeCode("wrong_length", "wrong_length", "not_equal"),
+		message: func(f Field) string {
+			return sizeMessage(f, "exactly")
+		},
+	})
+	registerRule("between", rule{
+		check: ruleBetween,
+		code:  constCode("out_of_range"),
+		message: func(f Field) string {
+			lo, hi, _ := strings.Cut(f.Param, " ")
+			return fmt.Sprintf("must be between %s and %s", lo, hi)
+		},
+		params: func(f Field) map[string]string {
+			lo, hi, _ := strings.Cut(f.Param, " ")
+			return map[string]string{"min": lo, "max": hi}
+		},
+	})
+	registerRule("oneof", rule{
+		check: func(f Field) bool {
+			return In(formatValue(f.Value), strings.Fields(f.Param)...)
+		},
+		code: constCode("not_allowed"),
+		message: func(f Field) string {
+			return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(f.Param), ", "))
+		},
+		params: valuesParams,
+	})
+	registerRule("notin", rule{
+		check: func(f Field) bool {
+			return NotIn(formatValue(f.Value), strings.Fields(f.Param)...)
+		},
+		code: constCode("not_allowed"),
+		message: func(f Field) string {
+			return fmt.Sprintf("must not be one of: %s", strings.Join(strings.Fields(f.Param), ", "))
+		},
+		params: valuesParams,
+	})
+	registerRule("email", rule{
+		check: func(f Field) bool {
+			return f.Value.Kind() == reflect.String && IsEmail(f.Value.String())
+		},
+		code:    constCode("invalid_email"),
+		message: constMessage("must be a valid email address"),
+	})
+	registerRule("url", rule{
+		check: func(f Field) bool {
+			return f.Value.Kind() == reflect.String && IsURL(f.Value.String())
+		},
+		code:    constCode("invalid_url"),
+		message: constMessage("must be a valid URL"),
+	})
+	registerRule("unique", rule{
+		check:   ruleUnique,
+		code:    constCode("duplicate"),
+		message: constMessage("must not contain duplicate values"),
+	})
+
+	registerStringRule("ulid", "invalid_ulid", "must be a valid ULID", IsULID)
+	registerStringRule("e164", "invalid_phone", "must be a phone number in E.164 format", IsE164)
+	registerStringRule("ip", "invalid_ip", "must be a valid IP address", IsIP)
+	registerStringRule("ipv4", "invalid_ipv4", "must be a valid IPv4 address", IsIPv4)
+	registerStringRule("ipv6", "invalid_ipv6", "must be a valid IPv6 address", IsIPv6)
+	registerStringRule("cidr", "invalid_cidr", "must be a valid CIDR subnet", IsCIDR)
+	registerStringRule("hostname", "invalid_hostname", "must be a valid hostname", IsHostname)
+	registerStringRule("date", "invalid_date", "must be a date in the format YYYY-MM-DD", IsISODate)
+	registerStringRule("datetime", "invalid_datetime", "must be a date and time in RFC 3339 format", IsISODateTime)
+	registerStringRule("duration", "invalid_duration", "must be an ISO 8601 duration", IsISODuration)
+	registerStringRule("country", "invalid_country", "must be an ISO 3166-1 alpha-2 country code", IsCountryCode)
+	registerStringRule("currency", "invalid_currency", "must be an ISO 4217 currency code", IsCurrencyCode)
+	registerStringRule("language", "invalid_language", "must be an ISO 639-1 language code", IsLanguageCode)
+	registerStringRule("iban", "invalid_iban", "must be a valid IBAN", IsIBAN)
+	registerStringRule("luhn", "invalid_checksum", "must have a valid checksum", IsLuhn)
+	registerStringRule("creditcard", "invalid_card_number", "must be a valid card number", IsCreditCard)
+	registerStringRule("semver", "invalid_semver", "must be a semantic version", IsSemver)
+	registerStringRule("hexcolor", "invalid_color", "must be a hex colour", IsHexColor)
+	registerStringRule("base64", "invalid_base64", "must be valid base64", IsBase64)
+	registerStringRule("json", "invalid_json", "must be valid JSON", IsJSON)
+
+	registerRule("uuid", rule{
+		check: func(f Field) bool {
+			if f.Value.Kind() != reflect.String {
+				return false
+			}
+			var versions []int
+			for _, version := range strings.Fields(f.Param) {
+				versions = append(versions, int(mustParseFloat(version)))
+			}
+			return IsUUID(f.Value.String(), versions...)
+		},
+		code: constCode("invalid_uuid"),
+		message: func(f Field) string {
+			if f.Param == "" {
+				return "must be a valid UUID"
+			}
+			return fmt.Sprintf("must be a valid UUID (version %s)", f.Param)
+		},
+		params: func(f Field) map[string]string {
+			if f.Param == "" {
+				return nil
+			}
+			return map[string]string{"version": f.Param}
+		},
+	})
+	registerRule("password", rule{
+		check: func(f Field) bool {
+			return f.Value.Kind() == reflect.String && IsStrongPassword(f.Value.String(), int(param(f)))
+		},
+		code:    constCode("weak_password"),
+		message: constMessage("must be a stronger password"),
+		params: func(f Field) map[string]string {
+			return map[string]string{"min_score": f.Param}
+		},
+	})
+}
+
+// constCode возвращает функцию, которая всегда возвращает код code.
+func constCode(code string) func(Field) string {
+	return func(Field) string { return code }
+}
+
+// constMessage возвращает функцию, которая всегда возвращает сообщение message.
+func constMessage(message string) func(Field) string {
+	return func(Field) string { return message }
+}
+
+// sizeCode выбирает код ошибки в зависимости от типа значения: строка, коллекция или число.
+func sizeCode(text, collection, number string) func(Field) string {
+	return func(f Field) string {
+		switch f.Value.Kind() {
+		case reflect.String:
+			return text
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return collection
+		default:
+			return number
+		}
+	}
+}
+
+// valuesParams возвращает параметры ошибки для правил со списком допустимых значений.
+func valuesParams(f Field) map[string]string {
+	return map[string]string{"values": strings.Join(strings.Fields(f.Param), " ")}
+}
+
+// ruleRequired проверяет, что значение задано: строка не пустая, срез или карта не пустые, указатель не nil.
+func ruleRequired(f Field) bool {
+	if !f.Value.IsValid() {
+		return false
+	}
+
+	switch f.Value.Kind() {
+	case reflect.String:
+		return NotBlank(f.Value.String())
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return f.Value.Len() > 0
+	default:
+		return !f.Value.IsZero()
+	}
+}
+
+// ruleMin проверяет, что число не меньше параметра, а длина строки, среза или карты - не меньше параметра.
+func ruleMin(f Field) bool {
+	if n, ok := number(f.Value); ok {
+		return n >= param(f)
+	}
+	if f.Value.Kind() == reflect.String {
+		return MinRunes(f.Value.String(), int(param(f)))
+	}
+	n, ok := length(f.Value)
+	return !ok || float64(n) >= param(f)
+}
+
+// ruleMax проверяет, что число не больше параметра, а длина строки, среза или карты - не больше параметра.
+func ruleMax(f Field) bool {
+	if n, ok := number(f.Value); ok {
+		return n <= param(f)
+	}
+	if f.Value.Kind() == reflect.String {
+		return MaxRunes(f.Value.String(), int(param(f)))
+	}
+	n, ok := length(f.Value)
+	return !ok || float64(n) <= param(f)
+}
+
+// ruleLen проверяет, что число равно параметру, а длина строки, среза или карты равна параметру.
+func ruleLen(f Field) bool {
+	if n, ok := number(f.Value); ok {
+		return n == param(f)
+	}
+	n, ok := length(f.Value)
+	return !ok || float64(n) == param(f)
+}
+
+// ruleBetween проверяет, что число (или длина) находится в пределах параметра вида "min max".
+func ruleBetween(f Field) bool {
+	lo, hi, _ := strings.Cut(f.Param, " ")
+	min, max := mustParseFloat(lo), mustParseFloat(hi)
+
+	if n, ok := number(f.Value); ok {
+		return Between(n, min, max)
+	}
+	n, ok := length(f.Value)
+	return !ok || Between(float64(n), min, max)
+}
+
+// ruleUnique проверяет, что в срезе или массиве нет повторяющихся значений.
+func ruleUnique(f Field) bool {
+	if f.Value.Kind() != reflect.Slice && f.Value.Kind() != reflect.Array {
+		return true
+	}
+
+	values := make([]string, f.Value.Len())
+	for i := range values {
+		values[i] = formatValue(f.Value.Index(i))
+	}
+
+	return NoDuplicates(values)
+}
+
+// sizeMessage формирует сообщение об ошибке для правил min, max и len в зависимости от типа значения.
+func sizeMessage(f Field, bound string) string {
+	switch f.Value.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("must be %s %s characters long", bound, f.Param)
+	case reflect.Slice, reflect.Array:
+		return fmt.Sprintf("must contain %s %s items", bound, f.Param)
+	case reflect.Map:
+		return fmt.Sprintf("must contain %s %s entries", bound, f.Param)
+	default:
+		if bound == "exactly" {
+			return fmt.Sprintf("must be equal to %s", f.Param)
+		}
+		return fmt.Sprintf("must be %s %s", bound, f.Param)
+	}
+}
+
+// number возвращает числовое значение для целых и вещественных типов.
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// length возвращает длину строки (в кодовых точках Unicode), среза, массива или карты.
+func length(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// formatValue возвращает строковое представление значения для сравнения со списками параметров.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// param возвращает числовой параметр правила.
+func param(f Field) float64 {
+	return mustParseFloat(f.Param)
+}
+
+// mustParseFloat разбирает числовой параметр правила. Некорректный параметр - ошибка в теге, поэтому вызывается паника.
+func mustParseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		panic(fmt.Sprintf("validator: invalid numeric rule parameter %q", s))
+	}
+	return f
+}
+
+// registerStringRule регистрирует правило для строковых значений на основе функции проверки из helpers.go.
+func registerStringRule(name, code, message string, check func(string) bool) {
+	registerRule(name, rule{
+		check: func(f Field) bool {
+			return f.Value.Kind() == reflect.String && check(f.Value.String())
+		},
+		code:    constCode(code),
+		message: constMessage(message),
+	})
+}
@@ -0,0 +1,308 @@
+//Код предоставляет функцию ValidateStruct для проверки структур по правилам из тегов validate.
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Field описывает проверяемое поле для правила валидации.
+type Field struct {
+	Value  reflect.Value // Значение поля (указатели разыменованы).
+	Param  string        // Параметр правила, например "3" для min=3.
+	Parent reflect.Value // Структура, содержащая поле.
+}
+
+// RuleFunc проверяет значение поля и возвращает true, если значение корректно.
+type RuleFunc func(field Field) bool
+
+// rule описывает зарегистрированное правило валидации.
+type rule struct {
+	check   RuleFunc
+	code    func(field Field) string            // Код ошибки; по умолчанию совпадает с именем правила.
+	message func(field Field) string            // Сообщение об ошибке.
+	params  func(field Field) map[string]string // Параметры ошибки; по умолчанию {имя правила: параметр}.
+	always  bool                                // Применять правило и к пустым значениям (правила обязательности).
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]rule{}
+)
+
+// validatorType - тип самого Validator; такие поля пропускаются при обходе структуры.
+var validatorType = reflect.TypeOf(Validator{})
+
+// RegisterRule регистрирует правило валидации с именем name, которое можно использовать в тегах validate.
+// В сообщении message подстрока {param} заменяется параметром правила. Кодом ошибки служит имя правила.
+// Повторная регистрация правила с тем же именем заменяет предыдущее.
+func RegisterRule(name string, check RuleFunc, message string) {
+	registerRule(name, rule{
+		check: check,
+		message: func(field Field) string {
+			return strings.ReplaceAll(message, "{param}", field.Param)
+		},
+	})
+}
+
+func registerRule(name string, r rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	if r.code == nil {
+		r.code = func(Field) string { return name }
+	}
+	if r.params == nil {
+		r.params = func(field Field) map[string]string {
+			if field.Param == "" {
+				return nil
+			}
+			return map[string]string{name: field.Param}
+		}
+	}
+
+	rules[name] = r
+}
+
+func lookupRule(name string) rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	r, ok := rules[name]
+	if !ok {
+		// Исключение в случае некорректного использования API.
+		panic(fmt.Sprintf("validator: unknown rule %q", name))
+	}
+
+	return r
+}
+
+// ValidateStruct проверяет структуру dst по правилам из тегов validate и возвращает Validator с ошибками.
+// Например: `validate:"required,min=3,max=50,email,oneof=a b"`.
+// Вложенные структуры, срезы и карты обходятся рекурсивно, ключи ошибок формируются по именам из тегов json
+// в виде пути, например items[2].name. Правила после dive применяются к каждому элементу среза или карты.
+func ValidateStruct(dst interface{}) Validator {
+	var v Validator
+	v.CheckStruct(dst)
+	return v
+}
+
+// CheckStruct проверяет структуру dst по правилам из тегов validate и добавляет ошибки в Validator.
+func (v *Validator) CheckStruct(dst interface{}) {
+	v.walk("", reflect.ValueOf(dst))
+}
+
+// walk рекурсивно обходит значение rv, проверяя поля структур с тегами validate.
+func (v *Validator) walk(path string, rv reflect.Value) {
+	rv = indirect(rv)
+	if !rv.IsValid() {
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return
+		}
+
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			if !field.IsExported() || field.Type == validatorType {
+				continue
+			}
+
+			tag := field.Tag.Get("validate")
+			if tag == "-" {
+				continue
+			}
+
+			// Поля встроенных структур без тега json проверяются на том же уровне вложенности.
+			key := jsonName(field)
+			if key == "-" {
+				continue
+			}
+			fieldPath := path
+			if !(field.Anonymous && !hasJSONName(field)) {
+				fieldPath = joinPath(path, key)
+			}
+
+			fieldValue := unwrapOptional(rv.Field(i))
+			if tag != "" {
+				fieldRules, elemRules := cutDive(splitRules(tag))
+
+				if !v.applyRules(fieldPath, fieldValue, rv, fieldRules) {
+					continue
+				}
+				if elemRules != nil {
+					v.applyElemRules(fieldPath, fieldValue, rv, elemRules)
+				}
+			}
+
+			v.walk(fieldPath, fieldValue)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			v.walk(fmt.Sprintf("%s[%d]", path, i), rv.Index(i))
+		}
+
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			v.walk(joinPath(path, fmt.Sprint(iter.Key().Interface())), iter.Value())
+		}
+	}
+}
+
+// applyRules применяет правила к значению поля. Возвращает false, если обход вложенных значений поля не нужен:
+// поле равно nil или не прошло проверку обязательности (required, required_if и т.п.).
+func (v *Validator) applyRules(path string, value, parent reflect.Value, names []string) bool {
+	// Кроме required, правила не применяются к nil-указателям, а при модификаторе omitempty - к пустым значениям.
+	omitEmpty := false
+	for _, name := range names {
+		if name == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	isNil := !value.IsValid() || (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) && value.IsNil()
+
+	for _, spec := range names {
+		name, param, _ := strings.Cut(spec, "=")
+		if name == "omitempty" {
+			continue
+		}
+
+		r := lookupRule(name)
+		if !r.always && (isNil || (omitEmpty && value.IsZero())) {
+			continue
+		}
+
+		field := Field{Value: indirect(value), Param: param, Parent: parent}
+		if !r.check(field) {
+			v.AddFieldDetail(path, FieldError{Code: r.code(field), Message: r.message(field), Params: r.params(field)})
+			if r.always {
+				return false
+			}
+		}
+	}
+
+	return !isNil
+}
+
+// applyElemRules применяет правила к каждому элементу среза, массива или карты.
+func (v *Validator) applyElemRules(path string, value, parent reflect.Value, names []string) {
+	value = indirect(value)
+	if !value.IsValid() {
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			v.applyRules(fmt.Sprintf("%s[%d]", path, i), value.Index(i), parent, names)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			v.applyRules(joinPath(path, fmt.Sprint(iter.Key().Interface())), iter.Value(), parent, names)
+		}
+	}
+}
+
+// splitRules разбивает тег validate на отдельные правила.
+func splitRules(tag string) []string {
+	var names []string
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// optional - интерфейс необязательных значений (например, request.Optional), правила для которых
+// применяются только к заданному значению.
+type optional interface {
+	OptionalValue() (interface{}, bool)
+}
+
+// unwrapOptional возвращает значение необязательного поля или нулевое reflect.Value, если значение не задано.
+func unwrapOptional(rv reflect.Value) reflect.Value {
+	if !rv.IsValid() || !rv.CanInterface() {
+		return rv
+	}
+	// У nil-указателя на необязательное значение нельзя вызвать OptionalValue: значение не задано.
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return reflect.Value{}
+	}
+
+	o, ok := rv.Interface().(optional)
+	if !ok {
+		return rv
+	}
+
+	value, set := o.OptionalValue()
+	if !set {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(value)
+}
+
+// cutDive разделяет правила на правила для самого поля и правила для элементов после dive.
+// Если dive не указан, второй результат равен nil.
+func cutDive(names []string) ([]string, []string) {
+	for i, name := range names {
+		if name == "dive" {
+			return names[:i], append([]string{}, names[i+1:]...)
+		}
+	}
+	return names, nil
+}
+
+// indirect разыменовывает указатели и интерфейсы. Для nil-указателя возвращается нулевое reflect.Value.
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// jsonName возвращает имя поля из тега json или имя самого поля.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// hasJSONName возвращает true, если имя поля явно задано в теге json.
+func hasJSONName(field reflect.StructField) bool {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name != ""
+}
+
+// joinPath объединяет путь к родительскому значению и ключ поля.
+func joinPath(path, key string) string {
+	switch {
+	case path == "":
+		return key
+	case key == "":
+		return path
+	case strings.HasPrefix(key, "["):
+		return path + key
+	default:
+		return path + "." + key
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
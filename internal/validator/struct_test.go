@@ -0,0 +1,220 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"apiapp/internal/request"
+)
+
+// codes возвращает коды ошибок каждого поля, чтобы сравнивать результаты проверки компактно.
+func codes(v Validator) map[string][]string {
+	result := map[string][]string{}
+	for key, details := range v.Details {
+		for _, detail := range details {
+			result[key] = append(result[key], detail.Code)
+		}
+	}
+	return result
+}
+
+type testAddress struct {
+	City    string `json:"city" validate:"required"`
+	Country string `json:"country" validate:"len=2"`
+}
+
+type testItem struct {
+	Name     string `json:"name" validate:"required"`
+	Quantity int    `json:"quantity" validate:"between=1 10"`
+}
+
+// Тестирование проверки структур по тегам validate и формирования путей к полям.
+func TestValidateStruct(t *testing.T) {
+	type Audit struct {
+		CreatedBy string `json:"created_by" validate:"required"`
+	}
+
+	type input struct {
+		Audit
+		Name     string            `json:"name" validate:"required,min=3,max=10"`
+		Email    string            `json:"email" validate:"omitempty,email"`
+		Role     string            `json:"role" validate:"oneof=admin user"`
+		Age      *int              `json:"age" validate:"min=18"`
+		Nickname *string           `json:"nickname" validate:"required"`
+		Address  testAddress       `json:"address"`
+		Billing  *testAddress      `json:"billing"`
+		Items    []testItem        `json:"items" validate:"min=1"`
+		Tags     []string          `json:"tags" validate:"max=3,dive,required,max=5"`
+		Labels   map[string]string `json:"labels" validate:"dive,notblank"`
+		Ignored  string            `json:"ignored" validate:"-"`
+		Hidden   testItem          `json:"-"`
+		NoTag    string
+		internal string
+	}
+
+	age, nickname := 16, "bob"
+	tests := []struct {
+		name  string
+		input input
+		want  map[string][]string
+	}{
+		{
+			name: "корректные значения",
+			input: input{
+				Audit: Audit{CreatedBy: "alice"},
+				Name:  "alice", Role: "admin", Nickname: &nickname,
+				Address: testAddress{City: "Berlin", Country: "DE"},
+				Items:   []testItem{{Name: "pen", Quantity: 2}},
+			},
+			want: map[string][]string{},
+		},
+		{
+			name: "ошибки полей верхнего уровня",
+			input: input{
+				Audit: Audit{CreatedBy: "alice"},
+				Name:  "al", Email: "not-an-email", Role: "guest", Age: &age,
+				Address: testAddress{City: "Berlin", Country: "DE"},
+				Items:   []testItem{{Name: "pen", Quantity: 2}},
+			},
+			want: map[string][]string{
+				"name":     {"too_short"},
+				"email":    {"invalid_email"},
+				"role":     {"not_allowed"},
+				"age":      {"too_small"},
+				"nickname": {"required"},
+			},
+		},
+		{
+			name: "вложенные структуры, срезы и карты",
+			input: input{
+				Name: "alice", Role: "user", Nickname: &nickname,
+				Address: testAddress{Country: "DEU"},
+				Billing: &testAddress{City: "Paris", Country: "F"},
+				Items:   []testItem{{Name: "pen", Quantity: 2}, {Quantity: 11}},
+				Tags:    []string{"a", "", "toolong"},
+				Labels:  map[string]string{"env": " ", "team": "api"},
+				Ignored: "",
+				Hidden:  testItem{},
+			},
+			want: map[string][]string{
+				"created_by":        {"required"},
+				"address.city":      {"required"},
+				"address.country":   {"wrong_length"},
+				"billing.country":   {"wrong_length"},
+				"items[1].name":     {"required"},
+				"items[1].quantity": {"out_of_range"},
+				"tags[1]":           {"required"},
+				"tags[2]":           {"too_long"},
+				"labels.env":        {"blank"},
+			},
+		},
+		{
+			name: "пустой срез",
+			input: input{
+				Audit: Audit{CreatedBy: "alice"},
+				Name:  "alice", Role: "user", Nickname: &nickname,
+				Address: testAddress{City: "Berlin", Country: "DE"},
+				Items:   []testItem{},
+				Tags:    []string{"a", "b", "c", "d"},
+			},
+			want: map[string][]string{
+				"items": {"too_few"},
+				"tags":  {"too_many"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		v := ValidateStruct(&tt.input)
+		if got := codes(v); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got: %v\nwant: %v", tt.name, got, tt.want)
+		}
+		if v.HasErrors() != (len(tt.want) > 0) {
+			t.Errorf("%s: unexpected HasErrors %v", tt.name, v.HasErrors())
+		}
+	}
+}
+
+// Тестирование кодов, параметров и сообщений встроенных правил.
+func TestValidateStructDetails(t *testing.T) {
+	input := struct {
+		Name     string   `json:"name" validate:"required,min=3"`
+		Count    int      `json:"count" validate:"max=10"`
+		Items    []string `json:"items" validate:"len=2"`
+		Size     int      `json:"size" validate:"between=1 5"`
+		Status   string   `json:"status" validate:"oneof=active blocked"`
+		Tags     []string `json:"tags" validate:"unique"`
+		Nickname string   `json:"nickname" validate:"notin=admin root"`
+	}{
+		Name: "", Count: 11, Items: []string{"a"}, Size: 0, Status: "new", Tags: []string{"a", "a"}, Nickname: "root",
+	}
+
+	v := ValidateStruct(input)
+
+	want := map[string][]FieldError{
+		"name":     {{Code: "required", Message: "must be provided"}},
+		"count":    {{Code: "too_large", Message: "must be at most 10", Params: map[string]string{"max": "10"}}},
+		"items":    {{Code: "wrong_length", Message: "must contain exactly 2 items", Params: map[string]string{"len": "2"}}},
+		"size":     {{Code: "out_of_range", Message: "must be between 1 and 5", Params: map[string]string{"min": "1", "max": "5"}}},
+		"status":   {{Code: "not_allowed", Message: "must be one of: active, blocked", Params: map[string]string{"values": "active blocked"}}},
+		"tags":     {{Code: "duplicate", Message: "must not contain duplicate values"}},
+		"nickname": {{Code: "not_allowed", Message: "must not be one of: admin, root", Params: map[string]string{"values": "admin root"}}},
+	}
+	if !reflect.DeepEqual(v.Details, want) {
+		t.Errorf("unexpected details:\n got: %+v\nwant: %+v", v.Details, want)
+	}
+
+	// После ошибки обязательности остальные правила поля не применяются, а в FieldErrors попадает первая ошибка.
+	if v.FieldErrors["name"] != "must be provided" || v.FieldErrors["size"] != "must be between 1 and 5" {
+		t.Errorf("unexpected field errors: %v", v.FieldErrors)
+	}
+}
+
+// Тестирование пользовательских правил.
+func TestRegisterRule(t *testing.T) {
+	RegisterRule("prefix", func(f Field) bool {
+		return strings.HasPrefix(f.Value.String(), f.Param)
+	}, "must start with {param}")
+
+	input := struct {
+		SKU string `json:"sku" validate:"prefix=SKU-"`
+	}{SKU: "123"}
+
+	v := ValidateStruct(input)
+	want := []FieldError{{Code: "prefix", Message: "must start with SKU-", Params: map[string]string{"prefix": "SKU-"}}}
+	if !reflect.DeepEqual(v.Details["sku"], want) {
+		t.Errorf("unexpected details %+v", v.Details["sku"])
+	}
+
+	// Неизвестное правило - ошибка в теге.
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for an unknown rule")
+		}
+	}()
+	ValidateStruct(struct {
+		Name string `validate:"bogus"`
+	}{})
+}
+
+// Тестирование проверки указателей на необязательные значения.
+func TestValidateStructOptionalPointer(t *testing.T) {
+	type input struct {
+		Name *request.Optional[string] `json:"name" validate:"min=3"`
+		Role *request.Optional[string] `json:"role" validate:"required"`
+	}
+
+	name := request.Some("al")
+	v := ValidateStruct(input{Name: &name})
+	want := map[string][]string{"name": {"too_short"}, "role": {"required"}}
+	if got := codes(v); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected errors %v", got)
+	}
+
+	// nil-указатель не задан: правила, кроме обязательности, к нему не применяются.
+	v = ValidateStruct(input{Role: &name})
+	if v.HasErrors() {
+		t.Errorf("unexpected errors %v", v.FieldErrors)
+	}
+}
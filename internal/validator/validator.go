@@ -2,10 +2,26 @@
 
 package validator
 
+import (
+	"fmt"
+	"strings"
+)
+
+// CodeInvalid - код ошибки по умолчанию для ошибок, добавленных без явного кода.
+const CodeInvalid = "invalid"
+
+// FieldError описывает одну ошибку валидации поля: стабильный код, параметры правила и сообщение для человека.
+type FieldError struct {
+	Code    string            `json:"code"`             // Стабильный код ошибки, например "too_short".
+	Message string            `json:"message"`          // Сообщение об ошибке.
+	Params  map[string]string `json:"params,omitempty"` // Параметры правила, например {"min": "3"}.
+}
+
 // Validator представляет собой структуру для управления ошибками валидации.
 type Validator struct {
-	Errors      []string          `json:",omitempty"` // Общие ошибки валидации.
-	FieldErrors map[string]string `json:",omitempty"` // Ошибки валидации для конкретных полей.
+	Errors      []string                `json:",omitempty"` // Общие ошибки валидации.
+	FieldErrors map[string]string       `json:",omitempty"` // Первая ошибка валидации для каждого поля (совместимый формат).
+	Details     map[string][]FieldError `json:",omitempty"` // Все ошибки валидации для каждого поля с кодами и параметрами.
 }
 
 // HasErrors возвращает true, если есть какие-либо ошибки валидации.
@@ -22,16 +38,27 @@ func (v *Validator) AddError(message string) {
 	v.Errors = append(v.Errors, message)
 }
 
-// AddFieldError добавляет ошибку валидации для конкретного поля.
+// AddFieldError добавляет ошибку валидации для конкретного поля с кодом CodeInvalid.
 func (v *Validator) AddFieldError(key, message string) {
+	v.AddFieldDetail(key, FieldError{Code: CodeInvalid, Message: message})
+}
+
+// AddFieldDetail добавляет ошибку валидации с кодом и параметрами для конкретного поля.
+// Все ошибки поля сохраняются в Details, а в FieldErrors остается только первая из них.
+func (v *Validator) AddFieldDetail(key string, fieldError FieldError) {
 	if v.FieldErrors == nil {
 		v.FieldErrors = map[string]string{}
 	}
+	if v.Details == nil {
+		v.Details = map[string][]FieldError{}
+	}
 
 	// Если для поля уже есть ошибка, она не будет перезаписана.
 	if _, exists := v.FieldErrors[key]; !exists {
-		v.FieldErrors[key] = message
+		v.FieldErrors[key] = fieldError.Message
 	}
+
+	v.Details[key] = append(v.Details[key], fieldError)
 }
 
 // Check добавляет общую ошибку валидации, если условие (ok) ложно.
@@ -47,3 +74,63 @@ func (v *Validator) CheckField(ok bool, key, message string) {
 		v.AddFieldError(key, message)
 	}
 }
+
+// CheckFieldDetail добавляет ошибку валидации с кодом и параметрами для конкретного поля, если условие (ok) ложно.
+func (v *Validator) CheckFieldDetail(ok bool, key string, fieldError FieldError) {
+	if !ok {
+		v.AddFieldDetail(key, fieldError)
+	}
+}
+
+// Merge добавляет все ошибки другого валидатора к текущему.
+func (v *Validator) Merge(other Validator) {
+	v.Nest("", other)
+}
+
+// Nest добавляет ошибки валидатора вложенного объекта или элемента массива, добавляя prefix к ключам полей.
+// Общие ошибки вложенного валидатора становятся ошибками поля prefix.
+// Например, v.Nest(validator.Path("items", 2), itemValidator) добавит ошибку поля "name" как "items[2].name".
+func (v *Validator) Nest(prefix string, child Validator) {
+	for _, message := range child.Errors {
+		if prefix == "" {
+			v.AddError(message)
+		} else {
+			v.AddFieldError(prefix, message)
+		}
+	}
+
+	for key, message := range child.FieldErrors {
+		details := child.Details[key]
+		if len(details) == 0 {
+			details = []FieldError{{Code: CodeInvalid, Message: message}}
+		}
+
+		for _, fieldError := range details {
+			v.AddFieldDetail(joinPath(prefix, key), fieldError)
+		}
+	}
+}
+
+// Compact возвращает копию валидатора без подробных ошибок (Details) для клиентов,
+// которые ожидают прежний формат JSON с одной ошибкой на поле.
+func (v Validator) Compact() Validator {
+	return Validator{Errors: v.Errors, FieldErrors: v.FieldErrors}
+}
+
+// Path формирует ключ поля в виде JSON-пути из имен полей и индексов элементов,
+// например Path("items", 2, "name") возвращает "items[2].name".
+func Path(parts ...interface{}) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		switch p := part.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", p)
+		default:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			fmt.Fprint(&sb, p)
+		}
+	}
+	return sb.String()
+}
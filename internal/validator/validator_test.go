@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Тестирование накопления подробных ошибок полей.
+func TestAddFieldDetail(t *testing.T) {
+	var v Validator
+	if v.HasErrors() {
+		t.Fatal("expected no errors in an empty validator")
+	}
+
+	v.AddFieldDetail("name", FieldError{Code: "required", Message: "must be provided"})
+	v.AddFieldDetail("name", FieldError{Code: "too_short", Message: "must be at least 3 characters long", Params: map[string]string{"min": "3"}})
+	v.AddFieldError("email", "is taken")
+	v.CheckFieldDetail(true, "age", FieldError{Code: "too_small", Message: "must be at least 18"})
+
+	// В FieldErrors остается первая ошибка поля, в Details - все ошибки.
+	wantFields := map[string]string{"name": "must be provided", "email": "is taken"}
+	if !reflect.DeepEqual(v.FieldErrors, wantFields) {
+		t.Errorf("unexpected field errors %v", v.FieldErrors)
+	}
+
+	wantDetails := map[string][]FieldError{
+		"name": {
+			{Code: "required", Message: "must be provided"},
+			{Code: "too_short", Message: "must be at least 3 characters long", Params: map[string]string{"min": "3"}},
+		},
+		"email": {{Code: CodeInvalid, Message: "is taken"}},
+	}
+	if !reflect.DeepEqual(v.Details, wantDetails) {
+		t.Errorf("unexpected details %+v", v.Details)
+	}
+
+	compact := v.Compact()
+	if compact.Details != nil || !reflect.DeepEqual(compact.FieldErrors, wantFields) {
+		t.Errorf("unexpected compact validator %+v", compact)
+	}
+}
+
+// Тестирование объединения валидаторов и добавления ошибок вложенных объектов.
+func TestNest(t *testing.T) {
+	var item Validator
+	item.AddError("item is locked")
+	item.AddFieldDetail("name", FieldError{Code: "required", Message: "must be provided"})
+	// Ошибка, добавленная напрямую в FieldErrors без Details, переносится с кодом CodeInvalid.
+	item.FieldErrors["sku"] = "is unknown"
+
+	var v Validator
+	v.AddFieldError("items[0].name", "is reserved")
+	v.Nest(Path("items", 0), item)
+
+	wantDetails := map[string][]FieldError{
+		"items[0]":      {{Code: CodeInvalid, Message: "item is locked"}},
+		"items[0].name": {{Code: CodeInvalid, Message: "is reserved"}, {Code: "required", Message: "must be provided"}},
+		"items[0].sku":  {{Code: CodeInvalid, Message: "is unknown"}},
+	}
+	if !reflect.DeepEqual(v.Details, wantDetails) {
+		t.Errorf("unexpected details %+v", v.Details)
+	}
+	if v.FieldErrors["items[0].name"] != "is reserved" || len(v.Errors) != 0 {
+		t.Errorf("unexpected errors %v, %v", v.FieldErrors, v.Errors)
+	}
+
+	// Merge сохраняет общие ошибки общими и не меняет ключи полей.
+	var merged Validator
+	merged.Merge(item)
+	if !reflect.DeepEqual(merged.Errors, []string{"item is locked"}) {
+		t.Errorf("unexpected errors %v", merged.Errors)
+	}
+	if !reflect.DeepEqual(merged.Details["name"], []FieldError{{Code: "required", Message: "must be provided"}}) || merged.FieldErrors["sku"] != "is unknown" {
+		t.Errorf("unexpected field errors %v, %+v", merged.FieldErrors, merged.Details)
+	}
+}
+
+// Тестирование формирования путей к полям.
+func TestPath(t *testing.T) {
+	tests := []struct {
+		parts []interface{}
+		want  string
+	}{
+		{[]interface{}{"name"}, "name"},
+		{[]interface{}{"items", 2, "name"}, "items[2].name"},
+		{[]interface{}{"matrix", 0, 1}, "matrix[0][1]"},
+		{[]interface{}{0, "name"}, "[0].name"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := Path(tt.parts...); got != tt.want {
+			t.Errorf("Path(%v): expected %q, got %q", tt.parts, tt.want, got)
+		}
+	}
+}
@@ -1,6 +1,106 @@
+// Пакет version предоставляет сведения о версии и сборке приложения: семантическую версию, заданную
+// при сборке, а также ревизию VCS, время коммита и версию Go из информации о сборке бинарного файла.
 package version
 
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// defaultVersion используется, если версия не задана при сборке.
+const defaultVersion = "0.0.1"
+
+// version задается при сборке флагом компоновщика, например:
+//
+//	go build -ldflags="-X apiapp/internal/version.version=1.2.3" ./cmd/api
+var version string
+
+// Info содержит сведения о сборке приложения.
+type Info struct {
+	Version    string `json:"version"`               // Семантическая версия.
+	Revision   string `json:"revision,omitempty"`    // Ревизия VCS (хеш коммита).
+	Dirty      bool   `json:"dirty"`                 // Сборка из рабочей копии с незафиксированными изменениями.
+	CommitTime string `json:"commit_time,omitempty"` // Время коммита в формате RFC 3339.
+	GoVersion  string `json:"go_version"`            // Версия Go, которой собран бинарный файл.
+}
+
+var (
+	readOnce sync.Once
+	info     Info
+)
+
 // Get возвращает текущую версию приложения.
 func Get() string {
-	return "0.0.1"
+	return Read().Version
+}
+
+// Read возвращает сведения о сборке. Версия берется из значения, заданного при сборке, а если оно
+// не задано - из defaultVersion. Ревизия, признак изменений и время коммита доступны, только если
+// бинарный файл собран командой go build из репозитория Git.
+func Read() Info {
+	readOnce.Do(func() {
+		info = Info{Version: version, GoVersion: runtime.Version()}
+		if info.Version == "" {
+			info.Version = defaultVersion
+		}
+
+		buildInfo, ok := debug.ReadBuildInfo()
+		if ok {
+			for _, setting := range buildInfo.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					info.Revision = setting.Value
+				case "vcs.modified":
+					info.Dirty = setting.Value == "true"
+				case "vcs.time":
+					info.CommitTime = setting.Value
+				}
+			}
+		}
+	})
+
+	return info
+}
+
+// String возвращает версию со сведениями о сборке, например "1.2.3 (a1b2c3d, dirty, 2024-05-01T10:00:00Z, go1.21.0)".
+func (i Info) String() string {
+	details := make([]string, 0, 4)
+	if i.Revision != "" {
+		details = append(details, shortRevision(i.Revision))
+	}
+	if i.Dirty {
+		details = append(details, "dirty")
+	}
+	if i.CommitTime != "" {
+		details = append(details, i.CommitTime)
+	}
+	details = append(details, i.GoVersion)
+
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
+
+// LogValue представляет сведения о сборке в логах в виде группы атрибутов.
+func (i Info) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("version", i.Version)}
+	if i.Revision != "" {
+		attrs = append(attrs, slog.String("revision", shortRevision(i.Revision)), slog.Bool("dirty", i.Dirty))
+	}
+	if i.CommitTime != "" {
+		attrs = append(attrs, slog.String("commit_time", i.CommitTime))
+	}
+	attrs = append(attrs, slog.String("go_version", i.GoVersion))
+
+	return slog.GroupValue(attrs...)
+}
+
+// shortRevision сокращает хеш коммита до 7 символов, как это делает Git.
+func shortRevision(revision string) string {
+	if len(revision) > 7 {
+		return revision[:7]
+	}
+	return revision
 }
@@ -0,0 +1,75 @@
+package version
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Тестирование чтения сведений о сборке.
+func TestRead(t *testing.T) {
+	// Тестовый бинарный файл собирается без флага компоновщика, поэтому используется версия по умолчанию.
+	got := Read()
+	if got.Version != defaultVersion {
+		t.Errorf("expected version %q, got %q", defaultVersion, got.Version)
+	}
+	if got.GoVersion != runtime.Version() {
+		t.Errorf("expected Go version %q, got %q", runtime.Version(), got.GoVersion)
+	}
+
+	// Повторный вызов возвращает те же сведения.
+	if Read() != got {
+		t.Errorf("expected Read to return the same info, got %+v and %+v", got, Read())
+	}
+	if Get() != got.Version {
+		t.Errorf("expected Get to return %q, got %q", got.Version, Get())
+	}
+}
+
+// Тестирование строкового представления сведений о сборке.
+func TestInfoString(t *testing.T) {
+	tests := []struct {
+		info Info
+		want string
+	}{
+		{Info{Version: "1.2.3", GoVersion: "go1.21.0"}, "1.2.3 (go1.21.0)"},
+		{Info{Version: "1.2.3", Revision: "a1b2c3d4e5f6", GoVersion: "go1.21.0"}, "1.2.3 (a1b2c3d, go1.21.0)"},
+		{Info{Version: "1.2.3", Revision: "a1b2", Dirty: true, CommitTime: "2024-05-01T10:00:00Z", GoVersion: "go1.21.0"}, "1.2.3 (a1b2, dirty, 2024-05-01T10:00:00Z, go1.21.0)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.info.String(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
+
+// Тестирование вывода сведений о сборке в лог.
+func TestInfoLogValue(t *testing.T) {
+	tests := []struct {
+		info Info
+		want string
+	}{
+		{Info{Version: "1.2.3", GoVersion: "go1.21.0"}, "build.version=1.2.3 build.go_version=go1.21.0"},
+		{Info{Version: "1.2.3", Revision: "a1b2c3d4e5f6", Dirty: true, CommitTime: "2024-05-01T10:00:00Z", GoVersion: "go1.21.0"}, "build.version=1.2.3 build.revision=a1b2c3d build.dirty=true build.commit_time=2024-05-01T10:00:00Z build.go_version=go1.21.0"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key != "build" {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		logger.Info("", "build", tt.info)
+
+		if got := strings.TrimSpace(buf.String()); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}